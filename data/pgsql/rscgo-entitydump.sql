@@ -207,6 +207,21 @@ CREATE TABLE public.prayers (
 
 ALTER TABLE public.prayers OWNER TO zach;
 
+--
+-- Name: rare_drops; Type: TABLE; Schema: public; Owner: zach
+--
+
+CREATE TABLE public.rare_drops (
+    tableid bigint,
+    itemid bigint,
+    minamount bigint,
+    maxamount bigint,
+    probability double precision
+);
+
+
+ALTER TABLE public.rare_drops OWNER TO zach;
+
 --
 -- Name: shop_items; Type: TABLE; Schema: public; Owner: zach
 --
@@ -275,6 +290,18 @@ CREATE TABLE public.spells (
 
 ALTER TABLE public.spells OWNER TO zach;
 
+--
+-- Name: staff_runes; Type: TABLE; Schema: public; Owner: zach
+--
+
+CREATE TABLE public.staff_runes (
+    itemid bigint,
+    runeid bigint
+);
+
+
+ALTER TABLE public.staff_runes OWNER TO zach;
+
 --
 -- Name: tiles; Type: TABLE; Schema: public; Owner: zach
 --
@@ -288,6 +315,35 @@ CREATE TABLE public.tiles (
 
 ALTER TABLE public.tiles OWNER TO zach;
 
+--
+-- Name: zone_points; Type: TABLE; Schema: public; Owner: zach
+--
+
+CREATE TABLE public.zone_points (
+    zoneid bigint,
+    idx bigint,
+    x bigint,
+    y bigint
+);
+
+
+ALTER TABLE public.zone_points OWNER TO zach;
+
+--
+-- Name: zones; Type: TABLE; Schema: public; Owner: zach
+--
+
+CREATE TABLE public.zones (
+    id bigint,
+    name text,
+    flags bigint,
+    levels bigint,
+    priority bigint
+);
+
+
+ALTER TABLE public.zones OWNER TO zach;
+
 --
 -- Data for Name: boundarys; Type: TABLE DATA; Schema: public; Owner: zach
 --
@@ -32434,6 +32490,13 @@ COPY public.npc_drops (npcid, itemid, minamount, maxamount, probability) FROM st
 344	33	1	75	0.2
 344	31	1	100	0.3
 361	31	1	200	0.1
+184	-1	1	1	0.0078125
+196	-1	1	1	0.0078125
+201	-1	1	1	0.0078125
+202	-1	1	1	0.0078125
+290	-1	1	1	0.0078125
+291	-1	1	1	0.0078125
+344	-1	1	1	0.0078125
 \.
 
 
@@ -36863,6 +36926,20 @@ COPY public.prayers (id, name, description, required_level, drain_rate) FROM std
 \.
 
 
+--
+-- Data for Name: rare_drops; Type: TABLE DATA; Schema: public; Owner: zach
+--
+
+COPY public.rare_drops (tableid, itemid, minamount, maxamount, probability) FROM stdin;
+1	160	1	1	0.4
+1	159	1	1	0.25
+1	158	1	1	0.15
+1	157	1	1	0.08
+1	526	1	1	0.06
+1	527	1	1	0.06
+\.
+
+
 --
 -- Data for Name: shop_items; Type: TABLE DATA; Schema: public; Owner: zach
 --
@@ -37170,6 +37247,26 @@ COPY public.spells (id, name, description, required_level, rune_amount, type, ex
 \.
 
 
+--
+-- Data for Name: staff_runes; Type: TABLE DATA; Schema: public; Owner: zach
+--
+
+COPY public.staff_runes (itemid, runeid) FROM stdin;
+101	33
+102	32
+103	34
+197	31
+615	31
+616	32
+617	33
+618	34
+682	31
+683	32
+684	33
+685	34
+\.
+
+
 --
 -- Data for Name: tiles; Type: TABLE DATA; Schema: public; Owner: zach
 --
@@ -37203,6 +37300,28 @@ COPY public.tiles (colour, unknown, objecttype) FROM stdin;
 \.
 
 
+--
+-- Data for Name: zone_points; Type: TABLE DATA; Schema: public; Owner: zach
+--
+
+COPY public.zone_points (zoneid, idx, x, y) FROM stdin;
+1	0	0	0
+1	1	344	432
+2	0	0	0
+2	1	344	313
+\.
+
+
+--
+-- Data for Name: zones; Type: TABLE DATA; Schema: public; Owner: zach
+--
+
+COPY public.zones (id, name, flags, levels, priority) FROM stdin;
+1	Wilderness	1	0	0
+2	Deep wilderness	5	0	1
+\.
+
+
 --
 -- Name: boundarys idx_16481_doors_pkey; Type: CONSTRAINT; Schema: public; Owner: zach
 --
@@ -0,0 +1,279 @@
+/*
+ * Copyright (c) 2020 Zachariah Knight <aeros.storkpk@gmail.com>
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted, provided that the above copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ *
+ */
+
+// combatsim runs melee fights between two loadouts offline, using the game's combat formula, and reports how
+// accurate each side is, how hard it hits, and how long it takes to win.
+//
+// Build it the same way as the server: go build -o bin/combatsim pkg/combatsim.go
+//
+// Each loadout is a TOML file.  Either give the ID of an NPC to take its stats from, or a player's levels along with
+// the item IDs of its equipment, the IDs of its active prayers and its fight mode (0 controlled, 1 aggressive,
+// 2 accurate, 3 defensive).  Equipment bonuses can also be given as raw aim, power and armour points.
+//
+//	attack = 60
+//	defense = 60
+//	strength = 60
+//	hits = 60
+//	mode = 1
+//	equipment = [81, 401, 402, 112]
+//	prayers = [1, 2]
+//
+// Examples:
+//	combatsim -a player.toml -d npc.toml
+//	combatsim -a player.toml -d player2.toml -n 100000 --seed 1234
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+
+	"github.com/BurntSushi/toml"
+	"github.com/jessevdk/go-flags"
+
+	"github.com/spkaeros/rscgo/pkg/config"
+	"github.com/spkaeros/rscgo/pkg/db"
+	"github.com/spkaeros/rscgo/pkg/definitions"
+	"github.com/spkaeros/rscgo/pkg/game/entity"
+	"github.com/spkaeros/rscgo/pkg/game/world"
+	"github.com/spkaeros/rscgo/pkg/isaac"
+	"github.com/spkaeros/rscgo/pkg/log"
+	rscRand "github.com/spkaeros/rscgo/pkg/rand"
+)
+
+var simFlags struct {
+	Attacker string `short:"a" long:"attacker" description:"The TOML loadout file of the mob that swings first" required:"true"`
+	Defender string `short:"d" long:"defender" description:"The TOML loadout file of the mob being attacked" required:"true"`
+	Fights   int    `short:"n" long:"fights" description:"How many fights to run" default:"10000"`
+	Seed     int    `short:"s" long:"seed" description:"Seed the fighters ISAAC generators with this, to repeat a run exactly; random when 0"`
+	Config   string `short:"c" long:"config" description:"Specify the TOML configuration file to load game settings from" default:"config.toml"`
+}
+
+//loadout A mob to simulate fights with, as read from a TOML file.
+type loadout struct {
+	Npc       *int
+	Attack    int
+	Defense   int
+	Strength  int
+	Hits      int
+	Mode      int
+	Equipment []int
+	Prayers   []int
+	Aim       int
+	Power     int
+	Armour    int
+}
+
+//fighter A world.Combatant built out of a loadout.
+type fighter struct {
+	name    string
+	npc     bool
+	skills  entity.SkillTable
+	prayers [15]bool
+	mode    int
+	aim     int
+	power   int
+	armour  int
+	magic   int
+	ranged  int
+	rng     *rand.Rand
+}
+
+func (f *fighter) Skills() *entity.SkillTable {
+	return &f.skills
+}
+
+func (f *fighter) PrayerActivated(i int) bool {
+	return i >= 0 && i < len(f.prayers) && f.prayers[i]
+}
+
+func (f *fighter) FightMode() int {
+	return f.mode
+}
+
+func (f *fighter) AimPoints() int {
+	return f.aim
+}
+
+func (f *fighter) PowerPoints() int {
+	return f.power
+}
+
+func (f *fighter) ArmourPoints() int {
+	return f.armour
+}
+
+func (f *fighter) MagicPoints() int {
+	return f.magic
+}
+
+func (f *fighter) RangedPoints() int {
+	return f.ranged
+}
+
+func (f *fighter) CombatRng() *rand.Rand {
+	return f.rng
+}
+
+//heal Puts the fighters hits back up to its maximum, ready for the next fight.
+func (f *fighter) heal() {
+	f.skills.SetCur(entity.StatHits, f.skills.Maximum(entity.StatHits))
+}
+
+//newFighter Reads the loadout in the provided TOML file, and returns a fighter with its stats and bonuses.
+func newFighter(file string, seed int) (*fighter, error) {
+	var l loadout
+	if _, err := toml.DecodeFile(file, &l); err != nil {
+		return nil, err
+	}
+	if seed == 0 {
+		seed = rscRand.Int()
+	}
+	// equipment bonuses start at 1, the same as they do for players in game
+	f := &fighter{name: file, mode: l.Mode, aim: 1 + l.Aim, power: 1 + l.Power, armour: 1 + l.Armour, magic: 1, ranged: 1, rng: rand.New(isaac.New(seed))}
+	levels := map[int]int{entity.StatAttack: l.Attack, entity.StatDefense: l.Defense, entity.StatStrength: l.Strength, entity.StatHits: l.Hits}
+	if l.Npc != nil {
+		def := definitions.Npc(*l.Npc)
+		if def.ID < 0 {
+			return nil, fmt.Errorf("no NPC with ID %d", *l.Npc)
+		}
+		f.name, f.npc = def.Name, true
+		levels = map[int]int{entity.StatAttack: def.Attack, entity.StatDefense: def.Defense, entity.StatStrength: def.Strength, entity.StatHits: def.Hits}
+	}
+	for stat, level := range levels {
+		if level < 1 {
+			level = 1
+		}
+		f.skills.SetMax(stat, level)
+		f.skills.SetCur(stat, level)
+	}
+	for _, id := range l.Equipment {
+		e := definitions.Equip(id)
+		if e == nil {
+			return nil, fmt.Errorf("item %d can not be equipped", id)
+		}
+		f.aim += e.Aim
+		f.power += e.Power
+		f.armour += e.Armour
+		f.magic += e.Magic
+		f.ranged += e.Ranged
+	}
+	for _, id := range l.Prayers {
+		if id < 0 || id >= len(f.prayers) {
+			return nil, fmt.Errorf("no prayer with ID %d", id)
+		}
+		f.prayers[id] = true
+	}
+	return f, nil
+}
+
+//swing Rolls a single melee attack from the fighter onto target, and returns its damage.
+func (f *fighter) swing(target *fighter) int {
+	if f.npc && (f.skills.Maximum(entity.StatStrength) < 5 || !target.npc && target.PrayerActivated(world.PrayerParalyzeMonster)) {
+		return 0
+	}
+	formula := world.DefaultCombatFormula
+	hit := world.RollHit(f, formula.AttackPoints(f), formula.DefensePoints(target), formula.MaxMeleeDamage(f))
+	return int(math.Min(float64(target.skills.Current(entity.StatHits)), float64(hit)))
+}
+
+//tally What one side did over every simulated fight.
+type tally struct {
+	swings, hits, damage, wins, winTicks int
+}
+
+func main() {
+	if _, err := flags.Parse(&simFlags); err != nil {
+		os.Exit(1)
+	}
+	config.TomlConfig.DataDir = "./data/"
+	config.TomlConfig.DbioDefs = config.TomlConfig.DataDir + "dbio.conf"
+	config.TomlConfig.Database.PlayerDriver = "sqlite3"
+	config.TomlConfig.Database.WorldDriver = "sqlite3"
+	config.TomlConfig.Database.PlayerDB = "file:./data/players.db"
+	config.TomlConfig.Database.WorldDB = "file:./data/world.db"
+	if _, err := toml.DecodeFile(simFlags.Config, &config.TomlConfig); err != nil {
+		log.Warn("Error decoding server config, using defaults:", err)
+	}
+	if _, err := toml.DecodeFile(config.TomlConfig.DbioDefs, &config.TomlConfig.Database); err != nil {
+		log.Warn("Error decoding database i/o config, using defaults:", err)
+	}
+	db.ConnectEntityService()
+	db.LoadItemDefinitions()
+	db.LoadNpcDefinitions()
+
+	seed := simFlags.Seed
+	attacker, err := newFighter(simFlags.Attacker, seed)
+	if err != nil {
+		log.Warn("Could not load attacker loadout:", err)
+		os.Exit(1)
+	}
+	if seed != 0 {
+		// both fighters rolling the same numbers would make for a strange fight
+		seed++
+	}
+	defender, err := newFighter(simFlags.Defender, seed)
+	if err != nil {
+		log.Warn("Could not load defender loadout:", err)
+		os.Exit(1)
+	}
+	if simFlags.Fights < 1 {
+		log.Warn("Invalid fight count; must be at least 1")
+		os.Exit(1)
+	}
+
+	fighters := [2]*fighter{attacker, defender}
+	var tallies [2]tally
+	for i := 0; i < simFlags.Fights; i++ {
+		attacker.heal()
+		defender.heal()
+		// in game, the fighters take turns swinging every 2 ticks, starting with the attacker
+		for round := 0; round < 10000; round++ {
+			turn := round % 2
+			swinger, target := fighters[turn], fighters[1-turn]
+			hit := swinger.swing(target)
+			tallies[turn].swings++
+			if hit > 0 {
+				tallies[turn].hits++
+				tallies[turn].damage += hit
+			}
+			target.skills.DecreaseCur(entity.StatHits, hit)
+			if target.skills.Current(entity.StatHits) <= 0 {
+				tallies[turn].wins++
+				tallies[turn].winTicks += (round + 1) * 2
+				break
+			}
+		}
+	}
+
+	formula := world.DefaultCombatFormula
+	fmt.Printf("%d fights of %v attacking %v\n", simFlags.Fights, attacker.name, defender.name)
+	for turn, f := range fighters {
+		target, t := fighters[1-turn], tallies[turn]
+		fmt.Printf("\n%v:\n", f.name)
+		fmt.Printf("\taccuracy %.0f, defense %.0f, max hit %.0f\n", formula.AttackPoints(f), formula.DefensePoints(f), formula.MaxMeleeDamage(f))
+		fmt.Printf("\tformula hit chance: %.2f%%\n", formula.HitChance(formula.AttackPoints(f), formula.DefensePoints(target))*100)
+		if t.swings == 0 {
+			continue
+		}
+		fmt.Printf("\thit rate: %.2f%% of %d swings\n", float64(t.hits)/float64(t.swings)*100, t.swings)
+		fmt.Printf("\taverage damage: %.3f per swing", float64(t.damage)/float64(t.swings))
+		if t.hits > 0 {
+			fmt.Printf(", %.3f per hit", float64(t.damage)/float64(t.hits))
+		}
+		fmt.Println()
+		fmt.Printf("\twon %d fights (%.2f%%)", t.wins, float64(t.wins)/float64(simFlags.Fights)*100)
+		if t.wins > 0 {
+			ticks := float64(t.winTicks) / float64(t.wins)
+			fmt.Printf(", killing in %.1f ticks (%.1f seconds) on average", ticks, ticks*world.TickMillis.Seconds())
+		}
+		fmt.Println()
+	}
+}
@@ -8,12 +8,21 @@ var TomlConfig struct {
 	Port              int    `toml:"port"`
 	MaxPlayers        int    `toml:"max_players"`
 	PacketHandlerFile string `toml:"packet_handler_table"`
+	Members           bool   `toml:"members"`
 	Database          struct {
 		PlayerDriver string `toml:"player_driver"`
 		WorldDriver  string `toml:"world_driver"`
 		PlayerDB     string `toml:"player_db"`
 		WorldDB      string `toml:"world_db"`
 	} `toml:"database"`
+	GroundItems struct {
+		PrivateTicks int `toml:"private_ticks"`
+		PublicTicks  int `toml:"public_ticks"`
+	} `toml:"ground_items"`
+	Snapshot struct {
+		File    string `toml:"file"`
+		Restore bool   `toml:"restore"`
+	} `toml:"snapshot"`
 	Crypto struct {
 		RsaKeyFile     string `toml:"rsa_key"`
 		HashSalt       string `toml:"hash_salt"`
@@ -106,3 +115,28 @@ func PlayerDriver() string {
 func WorldDriver() string {
 	return TomlConfig.Database.WorldDriver
 }
+
+//GroundItemPrivateTicks Returns how many game ticks a dropped item stays visible to only its owner
+func GroundItemPrivateTicks() int {
+	return TomlConfig.GroundItems.PrivateTicks
+}
+
+//GroundItemPublicTicks Returns how many game ticks a dropped item stays visible to everyone, before it disappears
+func GroundItemPublicTicks() int {
+	return TomlConfig.GroundItems.PublicTicks
+}
+
+//SnapshotFile Returns the file that world state snapshots are saved to and restored from
+func SnapshotFile() string {
+	return TomlConfig.Snapshot.File
+}
+
+//RestoreSnapshot Returns true if the world state should be restored from the snapshot file on startup
+func RestoreSnapshot() bool {
+	return TomlConfig.Snapshot.Restore
+}
+
+//MembersWorld Returns true if this is a members world, which lets players into members only zones
+func MembersWorld() bool {
+	return TomlConfig.Members
+}
@@ -2,6 +2,9 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"math"
 
 	"github.com/spkaeros/rscgo/pkg/config"
 	"github.com/spkaeros/rscgo/pkg/definitions"
@@ -14,7 +17,13 @@ type EntityService interface {
 	Boundarys() []definitions.BoundaryDefinition
 	Tiles() []definitions.TileDefinition
 	Items() []definitions.ItemDefinition
+	Equipment() []definitions.EquipmentDefinition
 	Npcs() []definitions.NpcDefinition
+	Spells() []definitions.SpellDefinition
+	Prayers() []definitions.PrayerDefinition
+	Drops() []definitions.DropTable
+	RareDrops() map[int][]definitions.Drop
+	StaffRunes() map[int]int
 }
 
 var DefaultEntityService *sqlService
@@ -114,26 +123,44 @@ func (s *sqlService) Items() (items []definitions.ItemDefinition) {
 		log.Error.Println("Couldn't load entity information from sql database:", err)
 		return
 	}
+	positions := make(map[int]int, len(items))
+	for i, item := range items {
+		positions[item.ID] = i
+	}
 	var id, skill, level int
 	for rows.Next() {
 		rows.Scan(&id, &skill, &level)
-		if items[id].Requirements == nil {
-			items[id].Requirements = make(map[int]int)
+		i, ok := positions[id]
+		if !ok {
+			continue
+		}
+		if items[i].Requirements == nil {
+			items[i].Requirements = make(map[int]int)
 		}
-		items[id].Requirements[skill] = level
+		items[i].Requirements[skill] = level
 	}
 	rows.Close()
 
-	rows, err = db.QueryContext(s.context, "SELECT id, sprite, type, armour_points, magic_points, prayer_points, range_points, weapon_aim_points, weapon_power_points, pos, femaleOnly FROM item_wieldable")
+	return
+}
+
+//Equipment attempts to load all the equipment definitions from the SQL service
+func (s *sqlService) Equipment() (equipment []definitions.EquipmentDefinition) {
+	s.Lock()
+	defer s.Unlock()
+	s.context = context.Background()
+	rows, err := s.connect(s.context).QueryContext(s.context, "SELECT id, sprite, type, armour_points, magic_points, prayer_points, range_points, weapon_aim_points, weapon_power_points, pos, femaleOnly FROM item_wieldable")
 	if err != nil {
-		log.Error.Println("Couldn't load entity information from sql database:", err)
+		log.Warn("Couldn't load entity definitions from sqlService:", err)
 		return
 	}
+	defer rows.Close()
+
 	// TODO: Integrate into ItemDefinition
 	for rows.Next() {
 		nextDef := definitions.EquipmentDefinition{}
 		rows.Scan(&nextDef.ID, &nextDef.Sprite, &nextDef.Type, &nextDef.Armour, &nextDef.Magic, &nextDef.Prayer, &nextDef.Ranged, &nextDef.Aim, &nextDef.Power, &nextDef.Position, &nextDef.Female)
-		definitions.Equipment = append(definitions.Equipment, nextDef)
+		equipment = append(equipment, nextDef)
 	}
 
 	return
@@ -160,29 +187,237 @@ func (s *sqlService) Npcs() (npcs []definitions.NpcDefinition) {
 	return
 }
 
+//Prayers attempts to load all the prayer definitions from the SQL service
+func (s *sqlService) Prayers() (prayers []definitions.PrayerDefinition) {
+	s.Lock()
+	defer s.Unlock()
+	s.context = context.Background()
+	db := s.connect(s.context)
+	rows, err := db.QueryContext(s.context, "SELECT id, name, description, required_level, drain_rate FROM prayers ORDER BY id")
+	if err != nil {
+		log.Warn("Couldn't load entity definitions from sqlService:", err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		nextDef := definitions.PrayerDefinition{}
+		rows.Scan(&nextDef.ID, &nextDef.Name, &nextDef.Description, &nextDef.Level, &nextDef.Drain)
+		// the table counts from 1, while the client counts from 0
+		nextDef.ID--
+		prayers = append(prayers, nextDef)
+	}
+
+	return
+}
+
+//Spells attempts to load all the spell definitions, along with their rune costs and missile strengths, from the SQL service
+func (s *sqlService) Spells() (spells []definitions.SpellDefinition) {
+	s.Lock()
+	defer s.Unlock()
+	s.context = context.Background()
+	db := s.connect(s.context)
+	rows, err := db.QueryContext(s.context, "SELECT id, name, description, required_level, type, experience FROM spells ORDER BY id")
+	if err != nil {
+		log.Warn("Couldn't load entity definitions from sqlService:", err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		nextDef := definitions.SpellDefinition{Runes: make(map[int]int)}
+		var experience int
+		rows.Scan(&nextDef.ID, &nextDef.Name, &nextDef.Description, &nextDef.Level, &nextDef.Type, &experience)
+		// the table counts experience in quarter points, like the client does; rounded to the nearest whole point,
+		// since that is all that Player.IncExp can give
+		nextDef.Experience = int(math.Round(float64(experience) / 4))
+		spells = append(spells, nextDef)
+	}
+	rows.Close()
+
+	positions := make(map[int]int, len(spells))
+	for i, spell := range spells {
+		positions[spell.ID] = i
+	}
+	rows, err = db.QueryContext(s.context, "SELECT spellID, itemID, amount FROM spell_runes")
+	if err != nil {
+		log.Error.Println("Couldn't load entity information from sql database:", err)
+		return
+	}
+	var id, runeID, amount int
+	for rows.Next() {
+		rows.Scan(&id, &runeID, &amount)
+		if i, ok := positions[id]; ok {
+			spells[i].Runes[runeID] += amount
+		}
+	}
+	rows.Close()
+
+	rows, err = db.QueryContext(s.context, "SELECT id, spell FROM spell_aggressive_level")
+	if err != nil {
+		log.Error.Println("Couldn't load entity information from sql database:", err)
+		return
+	}
+	var damage int
+	for rows.Next() {
+		rows.Scan(&id, &damage)
+		if i, ok := positions[id]; ok {
+			spells[i].Damage = damage
+		}
+	}
+	rows.Close()
+
+	return
+}
+
+//Drops attempts to load the drop table of every NPC from the SQL service.  Rows with a probability of 1 or more are
+// dropped on every kill, and rows with a negative item ID roll on the shared rare drop table with the negated ID.
+func (s *sqlService) Drops() (drops []definitions.DropTable) {
+	s.Lock()
+	defer s.Unlock()
+	s.context = context.Background()
+	rows, err := s.connect(s.context).QueryContext(s.context, "SELECT npcID, itemID, minAmount, maxAmount, probability FROM npc_drops ORDER BY npcID")
+	if err != nil {
+		log.Warn("Couldn't load entity definitions from sqlService:", err)
+		return
+	}
+	defer rows.Close()
+
+	positions := make(map[int]int)
+	var npcID int
+	for rows.Next() {
+		drop := definitions.Drop{}
+		rows.Scan(&npcID, &drop.ID, &drop.Min, &drop.Max, &drop.Weight)
+		i, ok := positions[npcID]
+		if !ok {
+			i = len(drops)
+			positions[npcID] = i
+			drops = append(drops, definitions.DropTable{NpcID: npcID, Rare: make(map[int]float64)})
+		}
+		switch {
+		case drop.ID < 0:
+			drops[i].Rare[-drop.ID] += drop.Weight
+		case drop.Weight >= 1:
+			drops[i].Always = append(drops[i].Always, drop)
+		default:
+			drops[i].Main = append(drops[i].Main, drop)
+		}
+	}
+
+	return
+}
+
+//StaffRunes attempts to load the staffs that stand in for runes from the SQL service, mapping each staff's item ID to
+// the ID of its rune.
+func (s *sqlService) StaffRunes() map[int]int {
+	s.Lock()
+	defer s.Unlock()
+	s.context = context.Background()
+	staffs := make(map[int]int)
+	rows, err := s.connect(s.context).QueryContext(s.context, "SELECT itemID, runeID FROM staff_runes")
+	if err != nil {
+		log.Warn("Couldn't load entity definitions from sqlService:", err)
+		return staffs
+	}
+	defer rows.Close()
+
+	var id, runeID int
+	for rows.Next() {
+		rows.Scan(&id, &runeID)
+		staffs[id] = runeID
+	}
+
+	return staffs
+}
+
+//RareDrops attempts to load the shared rare drop tables from the SQL service, keyed by their IDs.
+func (s *sqlService) RareDrops() map[int][]definitions.Drop {
+	s.Lock()
+	defer s.Unlock()
+	s.context = context.Background()
+	tables := make(map[int][]definitions.Drop)
+	rows, err := s.connect(s.context).QueryContext(s.context, "SELECT tableID, itemID, minAmount, maxAmount, probability FROM rare_drops ORDER BY tableID")
+	if err != nil {
+		log.Warn("Couldn't load entity definitions from sqlService:", err)
+		return tables
+	}
+	defer rows.Close()
+
+	var id int
+	for rows.Next() {
+		drop := definitions.Drop{}
+		rows.Scan(&id, &drop.ID, &drop.Min, &drop.Max, &drop.Weight)
+		tables[id] = append(tables[id], drop)
+	}
+
+	return tables
+}
+
+//Definitions attempts to load every type of definition from the SQL service at once, for reloading them while the
+// game is running.  Since a missing table would leave every definition of its type undefined, it is an error for any
+// of them to come back empty.  NPCs without drop tables still drop their bones, so the drop tables and rare drop
+// tables may be empty, and spells can always be cast with runes, so the staff runes may be too.
+func (s *sqlService) Definitions() (definitions.Tables, error) {
+	t := definitions.Tables{
+		Items:      s.Items(),
+		Equipment:  s.Equipment(),
+		Npcs:       s.Npcs(),
+		Scenary:    s.Objects(),
+		Boundaries: s.Boundarys(),
+		Tiles:      s.Tiles(),
+		Spells:     s.Spells(),
+		StaffRunes: s.StaffRunes(),
+		Prayers:    s.Prayers(),
+		Drops:      s.Drops(),
+		RareDrops:  s.RareDrops(),
+	}
+	if len(t.Items) == 0 || len(t.Equipment) == 0 || len(t.Npcs) == 0 || len(t.Scenary) == 0 || len(t.Boundaries) == 0 || len(t.Tiles) == 0 || len(t.Spells) == 0 || len(t.Prayers) == 0 {
+		return t, errors.New("could not load every type of definition from the world database")
+	}
+	return t, nil
+}
+
 //LoadObjectDefinitions Loads game object data into memory for quick access.
 func LoadObjectDefinitions() {
-	definitions.ScenaryObjects = DefaultEntityService.Objects()
+	definitions.LoadScenary(DefaultEntityService.Objects())
 }
 
 //LoadTileDefinitions Loads game tile attribute data into memory for quick access.
 func LoadTileDefinitions() {
-	definitions.TileOverlays = DefaultEntityService.Tiles()
+	definitions.LoadTiles(DefaultEntityService.Tiles())
 }
 
 //LoadBoundaryDefinitions Loads game boundary object data into memory for quick access.
 func LoadBoundaryDefinitions() {
-	definitions.BoundaryObjects = DefaultEntityService.Boundarys()
+	definitions.LoadBoundaries(DefaultEntityService.Boundarys())
 }
 
-//LoadItemDefinitions Loads game item data into memory for quick access.
+//LoadItemDefinitions Loads game item and equipment data into memory for quick access.
 func LoadItemDefinitions() {
-	definitions.Items = DefaultEntityService.Items()
+	definitions.LoadItems(DefaultEntityService.Items())
+	definitions.LoadEquipment(DefaultEntityService.Equipment())
 }
 
 //LoadNpcDefinitions Loads game NPC data into memory for quick access.
 func LoadNpcDefinitions() {
-	definitions.Npcs = DefaultEntityService.Npcs()
+	definitions.LoadNpcs(DefaultEntityService.Npcs())
+}
+
+//LoadSpellDefinitions Loads game spell data, and the staffs that stand in for runes, into memory for quick access.
+func LoadSpellDefinitions() {
+	definitions.LoadSpells(DefaultEntityService.Spells())
+	definitions.LoadStaffRunes(DefaultEntityService.StaffRunes())
+}
+
+//LoadPrayerDefinitions Loads game prayer data into memory for quick access.
+func LoadPrayerDefinitions() {
+	definitions.LoadPrayers(DefaultEntityService.Prayers())
+}
+
+//LoadDropDefinitions Loads game NPC drop tables, and the rare drop tables they share, into memory for quick access.
+func LoadDropDefinitions() {
+	definitions.LoadDrops(DefaultEntityService.Drops())
+	definitions.LoadRareDrops(DefaultEntityService.RareDrops())
 }
 
 //LoadObjectLocations Loads the game objects into memory from the SQLite3 database.
@@ -236,6 +471,50 @@ func LoadItemLocations() {
 	}
 }
 
+//LoadZones Loads the zones of the world, and the points outlining each of them, into memory from the SQLite3 database.
+func LoadZones() {
+	ctx := context.Background()
+	database := DefaultEntityService.sqlOpen(config.WorldDB())
+	rows, err := database.QueryContext(ctx, "SELECT id, name, flags, levels, priority FROM zones")
+	if err != nil {
+		log.Warn("Couldn't load SQLite3 database:", err)
+		return
+	}
+	defer rows.Close()
+	var zones []*world.Zone
+	byID := make(map[int]*world.Zone)
+	for rows.Next() {
+		zone := &world.Zone{}
+		rows.Scan(&zone.ID, &zone.Name, &zone.Flags, &zone.Levels, &zone.Priority)
+		zones = append(zones, zone)
+		byID[zone.ID] = zone
+	}
+	rows.Close()
+
+	rows, err = database.QueryContext(ctx, "SELECT zoneID, x, y FROM zone_points ORDER BY zoneID, idx")
+	if err != nil {
+		log.Warn("Couldn't load SQLite3 database:", err)
+		return
+	}
+	var id, x, y int
+	for rows.Next() {
+		rows.Scan(&id, &x, &y)
+		if zone, ok := byID[id]; ok {
+			zone.Points = append(zone.Points, world.NewLocation(x, y))
+		}
+	}
+
+	valid := zones[:0]
+	for _, zone := range zones {
+		if len(zone.Points) < 2 {
+			log.Warn("Zone", zone.ID, "("+zone.Name+") needs at least 2 points; ignoring it")
+			continue
+		}
+		valid = append(valid, zone)
+	}
+	world.LoadZones(valid)
+}
+
 //SaveObjectLocations Clears definitions.db game object locations and repopulates it with the current game locations.
 func SaveObjectLocations() int {
 	database := DefaultEntityService.sqlOpen(config.WorldDB())
@@ -287,3 +566,93 @@ func SaveObjectLocations() int {
 
 	return totalInserts
 }
+
+//SaveEdit Writes a single world edit to the spawn location tables in one transaction.  The row for edit.Before is deleted
+// and the row for edit.After is inserted; if either step fails, the transaction is rolled back and nothing is changed.
+func (s *sqlService) SaveEdit(edit *world.WorldEdit) error {
+	s.Lock()
+	defer s.Unlock()
+	database := s.database
+	if database == nil {
+		if database = s.sqlOpen(config.WorldDB()); database == nil {
+			return errors.New("could not connect to the world database")
+		}
+	}
+	tx, err := database.Begin()
+	if err != nil {
+		log.Warn("Error starting transaction for saving world edit:", err)
+		return err
+	}
+	if edit.Before != nil {
+		if err := deleteSpawn(tx, edit.Before); err != nil {
+			tx.Rollback()
+			log.Warn("Error deleting spawn location for world edit:", err)
+			return err
+		}
+	}
+	if edit.After != nil {
+		if err := insertSpawn(tx, edit.After); err != nil {
+			tx.Rollback()
+			log.Warn("Error inserting spawn location for world edit:", err)
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		log.Warn("Couldn't commit world edit:", err)
+		return err
+	}
+	return nil
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func insertSpawn(tx *sql.Tx, s *world.Spawn) (err error) {
+	switch s.Kind {
+	case world.SpawnObject:
+		_, err = tx.Exec("INSERT INTO game_object_locations(id, direction, x, y, boundary) VALUES(?, ?, ?, ?, ?)", s.ID, s.Direction, s.X, s.Y, boolInt(s.Boundary))
+	case world.SpawnNpc:
+		_, err = tx.Exec("INSERT INTO npc_locations(id, startX, minX, maxX, startY, minY, maxY) VALUES(?, ?, ?, ?, ?, ?, ?)", s.ID, s.X, s.MinX, s.MaxX, s.Y, s.MinY, s.MaxY)
+	case world.SpawnItem:
+		_, err = tx.Exec("INSERT INTO item_locations(id, x, y, amount, respawn) VALUES(?, ?, ?, ?, ?)", s.ID, s.X, s.Y, s.Amount, s.Respawn)
+	default:
+		err = errors.New("unknown spawn kind")
+	}
+	return
+}
+
+func deleteSpawn(tx *sql.Tx, s *world.Spawn) error {
+	var res sql.Result
+	var err error
+	switch s.Kind {
+	case world.SpawnObject:
+		res, err = tx.Exec("DELETE FROM game_object_locations WHERE id=? AND direction=? AND x=? AND y=? AND boundary=?", s.ID, s.Direction, s.X, s.Y, boolInt(s.Boundary))
+	case world.SpawnNpc:
+		res, err = tx.Exec("DELETE FROM npc_locations WHERE id=? AND startX=? AND minX=? AND maxX=? AND startY=? AND minY=? AND maxY=?", s.ID, s.X, s.MinX, s.MaxX, s.Y, s.MinY, s.MaxY)
+	case world.SpawnItem:
+		res, err = tx.Exec("DELETE FROM item_locations WHERE id=? AND x=? AND y=? AND amount=? AND respawn=?", s.ID, s.X, s.Y, s.Amount, s.Respawn)
+	default:
+		return errors.New("unknown spawn kind")
+	}
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count < 1 {
+		return errors.New("no saved spawn location matches " + s.String())
+	}
+	// Identical rows can't be told apart, so every one of them was deleted; put back all but the one being edited.
+	for ; count > 1; count-- {
+		if err := insertSpawn(tx, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package definitions
+
+//Drop A single item that an NPC can drop when it dies, and how many of it.
+type Drop struct {
+	ID  int
+	Min int
+	Max int
+	//Weight The chance, out of 1, that a roll on the table holding this drop lands on it.
+	Weight float64
+}
+
+//DropTable This represents everything that a single type of NPC is able to drop when it dies, besides its bones.
+type DropTable struct {
+	NpcID int
+	//Always Items that are dropped on every kill.
+	Always []Drop
+	//Main The weighted items that each kill rolls once for.  Whatever weight these and Rare leave out of 1 rolls nothing.
+	Main []Drop
+	//Rare Maps the ID of each shared rare drop table that the main roll can land on to its weight.
+	Rare map[int]float64
+}
+
+func (d DropTable) Defined() bool {
+	return d.NpcID > -1
+}
+
+//Drops returns the associated NPC drop table, or one with an NPC ID of -1 if none.
+func Drops(npcID int) DropTable {
+	if drops := Current().Drops; npcID >= 0 && npcID < len(drops) {
+		return drops[npcID]
+	}
+
+	return DropTable{NpcID: -1}
+}
+
+//RareDrops returns the shared rare drop table with the provided ID, or nil if none.
+func RareDrops(id int) []Drop {
+	return Current().RareDrops[id]
+}
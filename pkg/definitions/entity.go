@@ -1,5 +1,9 @@
 package definitions
 
+import (
+	"strings"
+)
+
 //ItemDefinition This represents a single definition for a single item in the game.
 type ItemDefinition struct {
 	ID           int
@@ -13,15 +17,14 @@ type ItemDefinition struct {
 	Requirements map[int]int
 }
 
-//Items This holds the defining characteristics for all of the game's items, ordered by ID.
-var Items []ItemDefinition
+func (d ItemDefinition) Defined() bool {
+	return d.ID > -1
+}
 
-//Item returns the associated item definition or nil if none.
+//Item returns the associated item definition, or one with an ID of -1 if none.
 func Item(id int) ItemDefinition {
-	for _, i := range Items {
-		if i.ID == id {
-			return i
-		}
+	if items := Current().Items; id >= 0 && id < len(items) {
+		return items[id]
 	}
 
 	return ItemDefinition{ID: -1}
@@ -42,15 +45,11 @@ type EquipmentDefinition struct {
 	Female   bool
 }
 
-//Equipment contains all of the equipment related data for the game
-var Equipment []EquipmentDefinition
-
-//Equip returns the associated equipment definition or nil if none.
+//Equip returns the associated equipment definition or nil if none.  The definition belongs to the tables in use,
+// and must not be modified.
 func Equip(id int) *EquipmentDefinition {
-	for _, e := range Equipment {
-		if e.ID == id {
-			return &e
-		}
+	if equipment := Current().Equipment; id >= 0 && id < len(equipment) && equipment[id].ID == id {
+		return &equipment[id]
 	}
 
 	return nil
@@ -72,15 +71,14 @@ type NpcDefinition struct {
 	Hostility   int
 }
 
-//Npcs This is used to cache the persistent NPC data in RAM for quick access when needed.
-var Npcs []NpcDefinition
+func (d NpcDefinition) Defined() bool {
+	return d.ID > -1
+}
 
-//Npc returns the associated NPC definition or nil if none.
+//Npc returns the associated NPC definition, or one with an ID of -1 if none.
 func Npc(id int) NpcDefinition {
-	for _, e := range Npcs {
-		if e.ID == id {
-			return e
-		}
+	if npcs := Current().Npcs; id >= 0 && id < len(npcs) {
+		return npcs[id]
 	}
 
 	return NpcDefinition{ID: -1}
@@ -109,6 +107,12 @@ func (d ScenaryDefinition) Passable() bool {
 	return !d.Door() && !d.Solid()
 }
 
+//Permeable Returns true if projectiles can fly over this object even though mobs can not walk through it, e.g fences,
+// tables and counters.
+func (d ScenaryDefinition) Permeable() bool {
+	return nameContains(d.Name, permeableScenary)
+}
+
 func (d ScenaryDefinition) Width() int {
 	return d.W
 }
@@ -121,14 +125,10 @@ func (d ScenaryDefinition) Defined() bool {
 	return d.ID > -1
 }
 
-//ScenaryObjects This is used to cache the persistent scenary object data in RAM for quick access when needed.
-var ScenaryObjects ScenaryDefinitions
-
+//Scenary returns the associated scenary object definition, or one with an ID of -1 if none.
 func Scenary(id int) ScenaryDefinition {
-	for _, o := range ScenaryObjects {
-		if o.ID == id {
-			return o
-		}
+	if scenary := Current().Scenary; id >= 0 && id < len(scenary) {
+		return scenary[id]
 	}
 
 	return ScenaryDefinition{ID: -1}
@@ -143,12 +143,9 @@ type TileDefinition struct {
 
 //gouraudReplacement A placeholder to represent that this tile should be colored specially on the client, using the gouraud shading alg
 const gouraudReplacement = 987654321
-//TileOverlays Cache for tile overlays.
-var TileOverlays []TileDefinition
-
 func TileOverlay(id int) TileDefinition {
-	if id < len(TileOverlays) && id > 0 {
-		return TileOverlays[id]
+	if tiles := Current().Tiles; id < len(tiles) && id > 0 {
+		return tiles[id]
 	}
 
 	return TileDefinition{Blocked: 1, Visible: 0, Color: gouraudReplacement}
@@ -180,6 +177,29 @@ func (d BoundaryDefinition) Passable() bool {
 	return !d.Solid()
 }
 
+//Permeable Returns true if projectiles can fly over this boundary even though mobs can not walk through it, e.g
+// fences, railings and low walls.
+func (d BoundaryDefinition) Permeable() bool {
+	return nameContains(d.Name, permeableBoundaries)
+}
+
+var (
+	//permeableScenary Names of the scenary objects that projectiles can fly over.
+	permeableScenary = []string{"fence", "railing", "table", "counter"}
+	//permeableBoundaries Names of the boundaries that projectiles can fly over.
+	permeableBoundaries = []string{"fence", "railing", "low wall", "battlement", "arrowslit"}
+)
+
+func nameContains(name string, parts []string) bool {
+	name = strings.ToLower(name)
+	for _, part := range parts {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	return false
+}
+
 func (d BoundaryDefinition) Width() int {
 	return 1
 }
@@ -191,14 +211,10 @@ func (d BoundaryDefinition) Height() int {
 type BoundaryDefinitions []BoundaryDefinition
 type ScenaryDefinitions []ScenaryDefinition
 
-//BoundaryObjectss This holds the defining characteristics for all of the game's boundary scene objects, ordered by ID.
-var BoundaryObjects BoundaryDefinitions
-
+//Boundary returns the associated boundary object definition, or one with an ID of -1 if none.
 func Boundary(id int) BoundaryDefinition {
-	for _, b := range BoundaryObjects {
-		if b.ID == id {
-			return b
-		}
+	if boundaries := Current().Boundaries; id >= 0 && id < len(boundaries) {
+		return boundaries[id]
 	}
 
 	return BoundaryDefinition{ID: -1}
@@ -266,5 +282,11 @@ const (
 	OverlayWaterFloor
 )
 
+//PermeableOverlay Returns true if projectiles can fly over tiles with the provided overlay, even though mobs can not
+// walk on them, e.g water and lava.
+func PermeableOverlay(id int) bool {
+	return id == OverlayWater || id == OverlayDarkWater || id == OverlayLava
+}
+
 //blockedOverlays An array filled with any overlay types that mobs aren't able to walk to from another tile
 var blockedOverlays = [...]int{OverlayWater, OverlayDarkWater, OverlayBlack, OverlayWhite, OverlayLava, OverlayBlack2, OverlayBlack3, OverlayBlack4}
@@ -0,0 +1,24 @@
+package definitions
+
+//PrayerDefinition This represents a single definition for a single prayer in the game.
+type PrayerDefinition struct {
+	ID          int
+	Name        string
+	Description string
+	Level       int
+	//Drain How quickly the prayer drains prayer points while it is active, relative to the other prayers.
+	Drain int
+}
+
+func (d PrayerDefinition) Defined() bool {
+	return d.ID > -1
+}
+
+//Prayer returns the associated prayer definition, or one with an ID of -1 if none.
+func Prayer(id int) PrayerDefinition {
+	if prayers := Current().Prayers; id >= 0 && id < len(prayers) {
+		return prayers[id]
+	}
+
+	return PrayerDefinition{ID: -1}
+}
@@ -0,0 +1,48 @@
+package definitions
+
+const (
+	//SpellTeleport Spells cast on yourself that take you somewhere else, ID 0
+	SpellTeleport = 0
+	//SpellMob Spells cast on an NPC or another player, e.g missiles and curses, ID 2
+	SpellMob = 2
+	//SpellItem Spells cast on an item, either in your inventory or on the ground, ID 3
+	SpellItem = 3
+	//SpellObject Spells cast on a scenary object, e.g charging orbs at an obelisk, ID 5
+	SpellObject = 5
+	//SpellSelf Spells cast on yourself that do not teleport, ID 6
+	SpellSelf = 6
+)
+
+//SpellDefinition This represents a single definition for a single spell in the game.
+type SpellDefinition struct {
+	ID          int
+	Name        string
+	Description string
+	Level       int
+	Type        int
+	//Experience The magic experience given for each cast, in whole points.
+	Experience int
+	//Runes Maps the ID of each rune the spell needs to how many of it are used up by each cast.
+	Runes map[int]int
+	//Damage The strength of the spell's missile, which is the most damage it can hit; 0 for spells that do not hit.
+	Damage int
+}
+
+func (d SpellDefinition) Defined() bool {
+	return d.ID > -1
+}
+
+//Spell returns the associated spell definition, or one with an ID of -1 if none.
+func Spell(id int) SpellDefinition {
+	if spells := Current().Spells; id >= 0 && id < len(spells) {
+		return spells[id]
+	}
+
+	return SpellDefinition{ID: -1}
+}
+
+//StaffRunes Returns a map of the item ID of every staff that provides an endless supply of a rune to the ID of that
+// rune.  Casting a spell while wielding one of these staffs uses up none of its rune.
+func StaffRunes() map[int]int {
+	return Current().StaffRunes
+}
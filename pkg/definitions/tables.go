@@ -0,0 +1,259 @@
+package definitions
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+//Tables Holds every type of definition that the game uses.  Each table is indexed by the definitions' IDs, with any IDs
+// that have no definition left in place as entries with an ID of -1.  Equipment is indexed by the ID of its item, and
+// the tile overlays keep the order they were loaded in.  Drop tables are indexed by the ID of their NPC, and the rare
+// drop tables that they share are keyed by their own IDs.  Staff runes map the item ID of each staff to its rune's ID.
+//
+// A set of tables is never modified once it is in use, so it can be read from any goroutine without locking; loading
+// definitions always puts a whole new set of tables in its place.
+type Tables struct {
+	Items      []ItemDefinition
+	Equipment  []EquipmentDefinition
+	Npcs       []NpcDefinition
+	Scenary    ScenaryDefinitions
+	Boundaries BoundaryDefinitions
+	Tiles      []TileDefinition
+	Spells     []SpellDefinition
+	StaffRunes map[int]int
+	Prayers    []PrayerDefinition
+	Drops      []DropTable
+	RareDrops  map[int][]Drop
+}
+
+var (
+	current atomic.Value
+	// storeLock keeps concurrent loaders from replacing each other's tables
+	storeLock sync.Mutex
+)
+
+func init() {
+	current.Store(&Tables{})
+}
+
+//Current Returns the definition tables in use.  When reading several definitions that need to agree with each other,
+// keep hold of the result rather than calling this again for each one.
+func Current() *Tables {
+	return current.Load().(*Tables)
+}
+
+//Store Indexes every table in t by ID, and then atomically swaps them in for the tables in use.
+func Store(t Tables) {
+	storeLock.Lock()
+	defer storeLock.Unlock()
+	t.Items = indexItems(t.Items)
+	t.Equipment = indexEquipment(t.Equipment)
+	t.Npcs = indexNpcs(t.Npcs)
+	t.Scenary = indexScenary(t.Scenary)
+	t.Boundaries = indexBoundaries(t.Boundaries)
+	t.Spells = indexSpells(t.Spells)
+	t.Prayers = indexPrayers(t.Prayers)
+	t.Drops = indexDrops(t.Drops)
+	current.Store(&t)
+}
+
+//update Swaps in a copy of the tables in use, after fn has changed it.
+func update(fn func(t *Tables)) {
+	storeLock.Lock()
+	defer storeLock.Unlock()
+	t := *Current()
+	fn(&t)
+	current.Store(&t)
+}
+
+//LoadItems Replaces the item definitions in use.
+func LoadItems(defs []ItemDefinition) {
+	update(func(t *Tables) {
+		t.Items = indexItems(defs)
+	})
+}
+
+//LoadEquipment Replaces the equipment definitions in use.
+func LoadEquipment(defs []EquipmentDefinition) {
+	update(func(t *Tables) {
+		t.Equipment = indexEquipment(defs)
+	})
+}
+
+//LoadNpcs Replaces the NPC definitions in use.
+func LoadNpcs(defs []NpcDefinition) {
+	update(func(t *Tables) {
+		t.Npcs = indexNpcs(defs)
+	})
+}
+
+//LoadScenary Replaces the scenary object definitions in use.
+func LoadScenary(defs []ScenaryDefinition) {
+	update(func(t *Tables) {
+		t.Scenary = indexScenary(defs)
+	})
+}
+
+//LoadBoundaries Replaces the boundary object definitions in use.
+func LoadBoundaries(defs []BoundaryDefinition) {
+	update(func(t *Tables) {
+		t.Boundaries = indexBoundaries(defs)
+	})
+}
+
+//LoadTiles Replaces the tile overlay definitions in use.
+func LoadTiles(defs []TileDefinition) {
+	update(func(t *Tables) {
+		t.Tiles = defs
+	})
+}
+
+//LoadSpells Replaces the spell definitions in use.
+func LoadSpells(defs []SpellDefinition) {
+	update(func(t *Tables) {
+		t.Spells = indexSpells(defs)
+	})
+}
+
+//LoadStaffRunes Replaces the staffs that stand in for runes in use.
+func LoadStaffRunes(staffs map[int]int) {
+	update(func(t *Tables) {
+		t.StaffRunes = staffs
+	})
+}
+
+//LoadPrayers Replaces the prayer definitions in use.
+func LoadPrayers(defs []PrayerDefinition) {
+	update(func(t *Tables) {
+		t.Prayers = indexPrayers(defs)
+	})
+}
+
+//LoadDrops Replaces the NPC drop tables in use.
+func LoadDrops(defs []DropTable) {
+	update(func(t *Tables) {
+		t.Drops = indexDrops(defs)
+	})
+}
+
+//LoadRareDrops Replaces the shared rare drop tables in use.
+func LoadRareDrops(tables map[int][]Drop) {
+	update(func(t *Tables) {
+		t.RareDrops = tables
+	})
+}
+
+//tableSize Returns how long a table must be to hold the highest of the n IDs that id returns.
+func tableSize(n int, id func(i int) int) int {
+	size := 0
+	for i := 0; i < n; i++ {
+		if id(i) >= size {
+			size = id(i) + 1
+		}
+	}
+	return size
+}
+
+func indexItems(defs []ItemDefinition) []ItemDefinition {
+	table := make([]ItemDefinition, tableSize(len(defs), func(i int) int { return defs[i].ID }))
+	for i := range table {
+		table[i].ID = -1
+	}
+	for _, d := range defs {
+		if d.ID >= 0 {
+			table[d.ID] = d
+		}
+	}
+	return table
+}
+
+func indexEquipment(defs []EquipmentDefinition) []EquipmentDefinition {
+	table := make([]EquipmentDefinition, tableSize(len(defs), func(i int) int { return defs[i].ID }))
+	for i := range table {
+		table[i].ID = -1
+	}
+	for _, d := range defs {
+		if d.ID >= 0 {
+			table[d.ID] = d
+		}
+	}
+	return table
+}
+
+func indexNpcs(defs []NpcDefinition) []NpcDefinition {
+	table := make([]NpcDefinition, tableSize(len(defs), func(i int) int { return defs[i].ID }))
+	for i := range table {
+		table[i].ID = -1
+	}
+	for _, d := range defs {
+		if d.ID >= 0 {
+			table[d.ID] = d
+		}
+	}
+	return table
+}
+
+func indexScenary(defs []ScenaryDefinition) ScenaryDefinitions {
+	table := make(ScenaryDefinitions, tableSize(len(defs), func(i int) int { return defs[i].ID }))
+	for i := range table {
+		table[i].ID = -1
+	}
+	for _, d := range defs {
+		if d.ID >= 0 {
+			table[d.ID] = d
+		}
+	}
+	return table
+}
+
+func indexBoundaries(defs []BoundaryDefinition) BoundaryDefinitions {
+	table := make(BoundaryDefinitions, tableSize(len(defs), func(i int) int { return defs[i].ID }))
+	for i := range table {
+		table[i].ID = -1
+	}
+	for _, d := range defs {
+		if d.ID >= 0 {
+			table[d.ID] = d
+		}
+	}
+	return table
+}
+
+func indexSpells(defs []SpellDefinition) []SpellDefinition {
+	table := make([]SpellDefinition, tableSize(len(defs), func(i int) int { return defs[i].ID }))
+	for i := range table {
+		table[i].ID = -1
+	}
+	for _, d := range defs {
+		if d.ID >= 0 {
+			table[d.ID] = d
+		}
+	}
+	return table
+}
+
+func indexPrayers(defs []PrayerDefinition) []PrayerDefinition {
+	table := make([]PrayerDefinition, tableSize(len(defs), func(i int) int { return defs[i].ID }))
+	for i := range table {
+		table[i].ID = -1
+	}
+	for _, d := range defs {
+		if d.ID >= 0 {
+			table[d.ID] = d
+		}
+	}
+	return table
+}
+
+func indexDrops(defs []DropTable) []DropTable {
+	table := make([]DropTable, tableSize(len(defs), func(i int) int { return defs[i].NpcID }))
+	for i := range table {
+		table[i].NpcID = -1
+	}
+	for _, d := range defs {
+		if d.NpcID >= 0 {
+			table[d.NpcID] = d
+		}
+	}
+	return table
+}
@@ -39,6 +39,7 @@ type Location interface {
 	Above() Location
 	Below() Location
 	Collides(Location) bool
+	InSight(Location) bool
 	PlaneY(bool) int
 	Hash() int
 	EuclideanDistance(o Location) float64
@@ -0,0 +1,295 @@
+/*
+ * Copyright (c) 2020 Zachariah Knight <aeros.storkpk@gmail.com>
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted, provided that the above copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ *
+ */
+
+//Package render draws the game's landscape and collision data into images, for debugging pathing and for generating
+// the maps on the website.
+//
+// Images are drawn with north facing up and west facing left, the same as the client's minimap.  As world X
+// coordinates increase toward the west, the highest X coordinate of an area is the leftmost column of its image.
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strconv"
+
+	"github.com/spkaeros/rscgo/pkg/definitions"
+	"github.com/spkaeros/rscgo/pkg/game/entity"
+	"github.com/spkaeros/rscgo/pkg/game/world"
+)
+
+//Layer A bitmask of the optional layers to draw on top of the landscape.
+type Layer int
+
+const (
+	//LayerWalls draws walls and diagonal walls from the collision masks.
+	LayerWalls Layer = 1 << iota
+	//LayerBlocks marks tiles that are entirely blocked, whether by an object or by their overlay.
+	LayerBlocks
+	//LayerNpcs draws every NPC's spawn point, with its wander bounds boxed around it.
+	LayerNpcs
+	//LayerItems marks tiles holding ground items.
+	LayerItems
+	//LayerObjectIDs writes the ID of each scenary object on to its tile.  Needs a Scale of at least 6 to be legible.
+	LayerObjectIDs
+	//LayerCollision is the set of layers needed to debug pathing.
+	LayerCollision = LayerWalls | LayerBlocks
+	//LayerAll is every layer.
+	LayerAll = LayerWalls | LayerBlocks | LayerNpcs | LayerItems | LayerObjectIDs
+)
+
+//planeHeight How many rows of tiles each plane of the world is made up of.
+const planeHeight = world.MaxY / 4
+
+//Options Describes what to draw.
+type Options struct {
+	// Area is the rectangle of world coordinates to draw.  Max is exclusive.
+	Area image.Rectangle
+	// Scale is how many pixels wide each tile is drawn.  Values below 1 are treated as 1.
+	Scale int
+	// Layers holds the optional layers to draw over the landscape.
+	Layers Layer
+}
+
+var (
+	wallColor    = color.RGBA{255, 255, 255, 255}
+	blockColor   = color.RGBA{200, 0, 0, 255}
+	npcColor     = color.RGBA{255, 255, 0, 255}
+	npcAreaColor = color.RGBA{160, 160, 0, 255}
+	itemColor    = color.RGBA{255, 0, 255, 255}
+	idColor      = color.RGBA{0, 255, 255, 255}
+	waterColor   = color.RGBA{36, 64, 160, 255}
+	lavaColor    = color.RGBA{200, 60, 0, 255}
+	blankColor   = color.RGBA{0, 0, 0, 255}
+)
+
+//groundColors The palette used by the client to color the ground texture of tiles without an overlay.
+var groundColors [256]color.RGBA
+
+func init() {
+	for i := 0; i < 64; i++ {
+		groundColors[i] = color.RGBA{uint8(255 - i*4), uint8(255 - int(float64(i)*1.75)), uint8(255 - i*4), 255}
+		groundColors[i+64] = color.RGBA{uint8(i * 3), 144, 0, 255}
+		groundColors[i+128] = color.RGBA{uint8(192 - int(float64(i)*1.5)), uint8(144 - int(float64(i)*1.5)), 0, 255}
+		groundColors[i+192] = color.RGBA{uint8(96 - int(float64(i)*1.5)), uint8(48 + int(float64(i)*1.5)), 0, 255}
+	}
+}
+
+//PlaneArea Returns the area of world coordinates that make up the provided plane.
+func PlaneArea(plane int) image.Rectangle {
+	return image.Rect(0, plane*planeHeight, world.MaxX, (plane+1)*planeHeight)
+}
+
+//WritePNG Renders the area described by opts, and encodes it to w as a PNG image.
+func WritePNG(w io.Writer, opts Options) error {
+	return png.Encode(w, Render(opts))
+}
+
+//Render Draws the area described by opts into a new image, and returns it.
+func Render(opts Options) *image.RGBA {
+	if opts.Scale < 1 {
+		opts.Scale = 1
+	}
+	area := opts.Area.Canon()
+	r := &renderer{area: area, scale: opts.Scale,
+		img: image.NewRGBA(image.Rect(0, 0, area.Dx()*opts.Scale, area.Dy()*opts.Scale))}
+	for x := area.Min.X; x < area.Max.X; x++ {
+		for y := area.Min.Y; y < area.Max.Y; y++ {
+			r.fillTile(x, y, tileColor(world.LandscapeData(x, y)))
+		}
+	}
+	if opts.Layers&(LayerWalls|LayerBlocks) != 0 {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			for y := area.Min.Y; y < area.Max.Y; y++ {
+				r.drawCollision(x, y, world.CollisionData(x, y), opts.Layers)
+			}
+		}
+	}
+	if opts.Layers&LayerItems != 0 {
+		r.rangeRegions(func(x, y int) {
+			world.Region(x, y).Items.Range(func(e entity.Entity) {
+				if i, ok := e.(*world.GroundItem); ok && r.contains(i.X(), i.Y()) {
+					r.drawDot(i.X(), i.Y(), itemColor)
+				}
+			})
+		})
+	}
+	if opts.Layers&LayerObjectIDs != 0 {
+		r.rangeRegions(func(x, y int) {
+			world.Region(x, y).Objects.Range(func(e entity.Entity) {
+				if o, ok := e.(*world.Object); ok && !o.Boundary && r.contains(o.X(), o.Y()) {
+					r.drawNumber(o.X(), o.Y(), o.ID)
+				}
+			})
+		})
+	}
+	if opts.Layers&LayerNpcs != 0 {
+		world.Npcs.RangeNpcs(func(n *world.NPC) bool {
+			if r.contains(n.StartPoint.X(), n.StartPoint.Y()) {
+				r.drawBox(n.Boundaries[0].X(), n.Boundaries[0].Y(), n.Boundaries[1].X(), n.Boundaries[1].Y(), npcAreaColor)
+				r.drawDot(n.StartPoint.X(), n.StartPoint.Y(), npcColor)
+			}
+			return false
+		})
+	}
+	return r.img
+}
+
+//tileColor Returns the color a tile should be drawn, using its overlay if it has one, or its ground texture otherwise.
+func tileColor(tile world.TileData) color.RGBA {
+	overlay := int(tile.Overlay)
+	if overlay == 250 {
+		// -6 overflows to 250, and is water tile
+		overlay = definitions.OverlayWater
+	}
+	switch overlay {
+	case definitions.OverlayBlank:
+		return groundColors[tile.Texture]
+	case definitions.OverlayWater, definitions.OverlayDarkWater:
+		return waterColor
+	case definitions.OverlayLava:
+		return lavaColor
+	case definitions.OverlayBlack, definitions.OverlayBlack2, definitions.OverlayBlack3, definitions.OverlayBlack4:
+		return blankColor
+	}
+	if tiles := definitions.Current().Tiles; overlay-1 < len(tiles) {
+		if c := tiles[overlay-1].Color; c < 0 {
+			// Negative colors are 15-bit RGB, stored as -(rgb+1)
+			rgb := -(c + 1)
+			return color.RGBA{uint8((rgb >> 10 & 0x1F) << 3), uint8((rgb >> 5 & 0x1F) << 3), uint8((rgb & 0x1F) << 3), 255}
+		}
+	}
+	// Positive colors are texture IDs, which we don't have, so just use a neutral color for them
+	return color.RGBA{128, 128, 128, 255}
+}
+
+type renderer struct {
+	area  image.Rectangle
+	scale int
+	img   *image.RGBA
+}
+
+func (r *renderer) contains(x, y int) bool {
+	return image.Pt(x, y).In(r.area)
+}
+
+//origin Returns the image coordinates of the top-left pixel of the tile at x,y.
+func (r *renderer) origin(x, y int) (int, int) {
+	return (r.area.Max.X - 1 - x) * r.scale, (y - r.area.Min.Y) * r.scale
+}
+
+//rangeRegions Calls fn with a point inside of each region that overlaps the rendered area.
+func (r *renderer) rangeRegions(fn func(x, y int)) {
+	for x := r.area.Min.X - r.area.Min.X%world.RegionSize; x < r.area.Max.X; x += world.RegionSize {
+		for y := r.area.Min.Y - r.area.Min.Y%world.RegionSize; y < r.area.Max.Y; y += world.RegionSize {
+			if world.WithinWorld(x, y) {
+				fn(x, y)
+			}
+		}
+	}
+}
+
+func (r *renderer) fillTile(x, y int, c color.Color) {
+	px, py := r.origin(x, y)
+	draw.Draw(r.img, image.Rect(px, py, px+r.scale, py+r.scale), image.NewUniform(c), image.ZP, draw.Src)
+}
+
+func (r *renderer) drawCollision(x, y int, mask world.CollisionMask, layers Layer) {
+	px, py := r.origin(x, y)
+	last := r.scale - 1
+	if layers&LayerBlocks != 0 && mask&world.ClipFullBlock != 0 {
+		inset := r.scale / 4
+		draw.Draw(r.img, image.Rect(px+inset, py+inset, px+r.scale-inset, py+r.scale-inset), image.NewUniform(blockColor), image.ZP, draw.Over)
+		if r.scale < 4 {
+			r.img.Set(px, py, blockColor)
+		}
+	}
+	if layers&LayerWalls == 0 {
+		return
+	}
+	for i := 0; i < r.scale; i++ {
+		if mask&world.ClipNorth != 0 {
+			r.img.Set(px+i, py, wallColor)
+		}
+		if mask&world.ClipSouth != 0 {
+			r.img.Set(px+i, py+last, wallColor)
+		}
+		// East is toward lower X coordinates, which are drawn further right
+		if mask&world.ClipEast != 0 {
+			r.img.Set(px+last, py+i, wallColor)
+		}
+		if mask&world.ClipWest != 0 {
+			r.img.Set(px, py+i, wallColor)
+		}
+		if mask&world.ClipSwNe != 0 {
+			r.img.Set(px+i, py+last-i, wallColor)
+		}
+		if mask&world.ClipSeNw != 0 {
+			r.img.Set(px+i, py+i, wallColor)
+		}
+	}
+}
+
+func (r *renderer) drawDot(x, y int, c color.Color) {
+	px, py := r.origin(x, y)
+	size := r.scale / 2
+	if size < 1 {
+		size = 1
+	}
+	inset := (r.scale - size) / 2
+	draw.Draw(r.img, image.Rect(px+inset, py+inset, px+inset+size, py+inset+size), image.NewUniform(c), image.ZP, draw.Src)
+}
+
+//drawBox Outlines the tiles within the bounds (x1,y1) and (x2,y2), inclusive.
+func (r *renderer) drawBox(x1, y1, x2, y2 int, c color.Color) {
+	if x1 > x2 {
+		x1, x2 = x2, x1
+	}
+	if y1 > y2 {
+		y1, y2 = y2, y1
+	}
+	left, top := r.origin(x2, y1)
+	right, bottom := r.origin(x1, y2)
+	right += r.scale - 1
+	bottom += r.scale - 1
+	for px := left; px <= right; px++ {
+		r.img.Set(px, top, c)
+		r.img.Set(px, bottom, c)
+	}
+	for py := top; py <= bottom; py++ {
+		r.img.Set(left, py, c)
+		r.img.Set(right, py, c)
+	}
+}
+
+//digits A 3x5 pixel font for the numbers 0-9, one row per byte with the low 3 bits of each used as pixels.
+var digits = [10][5]byte{
+	{7, 5, 5, 5, 7}, {2, 6, 2, 2, 7}, {7, 1, 7, 4, 7}, {7, 1, 3, 1, 7}, {5, 5, 7, 1, 1},
+	{7, 4, 7, 1, 7}, {7, 4, 7, 5, 7}, {7, 1, 1, 1, 1}, {7, 5, 7, 5, 7}, {7, 5, 7, 1, 7},
+}
+
+//drawNumber Writes n in small print, starting at the top-left corner of the tile at x,y.
+func (r *renderer) drawNumber(x, y, n int) {
+	if r.scale < 6 {
+		return
+	}
+	px, py := r.origin(x, y)
+	for i, c := range strconv.Itoa(n) {
+		for row, bits := range digits[c-'0'] {
+			for col := 0; col < 3; col++ {
+				if bits&(4>>col) != 0 {
+					r.img.Set(px+1+i*4+col, py+1+row, idColor)
+				}
+			}
+		}
+	}
+}
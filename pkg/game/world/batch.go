@@ -0,0 +1,77 @@
+/*
+ * Copyright (c) 2020 Zachariah Knight <aeros.storkpk@gmail.com>
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted, provided that the above copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ *
+ */
+
+package world
+
+import (
+	"strconv"
+
+	"github.com/spkaeros/rscgo/pkg/tasks"
+)
+
+//Batch Makes the player repeat a skill action up to times times, or for as long as it can when times is 0, spending
+// ticks game ticks on each repetition.  start is called as each repetition begins, e.g to check for its ingredients and
+// tell the player what it is doing, and returns false to end the batch there instead.  action is called once the ticks
+// have passed, to use up the ingredients and finish the repetition off.
+//
+// The player is kept in the MSBatching state while the batch goes on, and is told how many of the repetitions are done
+// after each one when there is more than one.  Walking away, getting into a fight or logging out cancels the
+// repetition under way, and filling up its inventory stops the batch before the next one; either way the player is
+// told how far it got.  Blocks until the batch is over, and returns how many repetitions were finished.
+func (p *Player) Batch(ticks, times int, start func() bool, action func()) int {
+	if !p.HasState(MSBatching) {
+		p.AddState(MSBatching)
+	}
+	defer func() {
+		if p.HasState(MSBatching) {
+			p.RemoveState(MSBatching)
+		}
+	}()
+	done := 0
+	for times <= 0 || done < times {
+		if done > 0 && p.batchInterrupted() {
+			p.batchStopped(done, times)
+			break
+		}
+		slots := p.Inventory.Size()
+		if !start() {
+			break
+		}
+		tasks.Stall(ticks)
+		if p.batchInterrupted() {
+			// walked away or got into a fight while this repetition was under way, so it never finishes
+			p.batchStopped(done, times)
+			break
+		}
+		action()
+		done++
+		if times > 1 {
+			p.Message(strconv.Itoa(done) + " of " + strconv.Itoa(times) + " done")
+		}
+		if p.Inventory.Size() > slots && p.Inventory.Size() >= p.Inventory.Capacity {
+			// this repetition took the last free slot, so there is nowhere to put anything the next one makes
+			p.Message("Your inventory is full")
+			p.batchStopped(done, times)
+			break
+		}
+	}
+	return done
+}
+
+//batchStopped Tells the player how far it got through a batch that was cut short, if it was meant to go on longer.
+func (p *Player) batchStopped(done, times int) {
+	if times > 1 && done < times && p.Connected() {
+		p.Message("You stop after " + strconv.Itoa(done) + " of " + strconv.Itoa(times))
+	}
+}
+
+//batchInterrupted Returns true if the player did something that cancels the skill action it is repeating, or left.
+func (p *Player) batchInterrupted() bool {
+	return !p.Connected() || !p.HasState(MSBatching) || p.IsFighting()
+}
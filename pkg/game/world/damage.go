@@ -3,6 +3,17 @@ package world
 import `github.com/spkaeros/rscgo/pkg/game/entity`
 import `math`
 
+const (
+	//DamageMelee The kind of damage dealt by melee hits.
+	DamageMelee = iota
+	//DamageMagic The kind of damage dealt by combat spells.
+	DamageMagic
+	//DamageRanged The kind of damage dealt by arrows and bolts.
+	DamageRanged
+	//DamagePoison The kind of damage dealt by poison wearing on a mob.
+	DamagePoison
+)
+
 type HitSplat struct {
 	Owner entity.MobileEntity
 	Damage int
@@ -21,6 +32,7 @@ func (p *Player) DamageFrom(m entity.MobileEntity, damage int, kind int) bool {
 		if attacker := AsPlayer(m); attacker != nil {
 			attacker.PlaySound("victory")
 		}
+		p.SetVar("deathBlow", kind)
 		p.Killed(m)
 		return true
 	}
@@ -45,12 +57,15 @@ func (n *NPC) DamageFrom(m entity.MobileEntity, damage int, kind int) bool {
 	splat := NewHitsplat(n, damage)
 	n.enqueueArea(npcEvents, splat)
 	n.Skills().SetCur(entity.StatHits, n.Skills().Current(entity.StatHits) - damage)
-	if damage > 0 && m.IsPlayer() {
-		if kind == 0 {
+	if damage > 0 && m != nil && m.IsPlayer() {
+		if kind == DamageMelee || kind == DamageRanged || kind == DamagePoison {
 			n.meleeRangeDamage.Put(AsPlayer(m).UsernameHash(), damage)
-		} else if kind == 1 {
+		} else if kind == DamageMagic {
 			n.magicDamage.Put(AsPlayer(m).UsernameHash(), damage)
 		}
+		if kind == DamageRanged {
+			n.rangedDamage.Put(AsPlayer(m).UsernameHash(), damage)
+		}
 	}
 	if n.Skills().Current(entity.StatHits) <= 0 {
 		if attacker := AsPlayer(m); attacker != nil {
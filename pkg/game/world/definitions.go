@@ -0,0 +1,44 @@
+/*
+ * Copyright (c) 2020 Zachariah Knight <aeros.storkpk@gmail.com>
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted, provided that the above copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ *
+ */
+
+package world
+
+import (
+	"errors"
+
+	"github.com/spkaeros/rscgo/pkg/definitions"
+)
+
+//DefinitionService Loads every type of definition from the backing store of the world's definitions.
+type DefinitionService interface {
+	Definitions() (definitions.Tables, error)
+}
+
+//DefaultDefinitionService The service used to reload definitions while the game is running.
+var DefaultDefinitionService DefinitionService
+
+//ErrNoDefinitionService is returned when definitions are reloaded without a definition service to load them from.
+var ErrNoDefinitionService = errors.New("no definition service is available to reload definitions from")
+
+//ReloadDefinitions Loads every definition again using DefaultDefinitionService, and swaps them all in at once, so that
+// nothing ever sees a mix of old and new definitions.  If anything fails to load, the definitions in use are kept.
+//
+// Anything that was worked out from a definition when it was spawned, such as the collision masks of objects and the
+// skill levels of NPCs, keeps using the old definition until it is spawned again.
+func ReloadDefinitions() error {
+	if DefaultDefinitionService == nil {
+		return ErrNoDefinitionService
+	}
+	tables, err := DefaultDefinitionService.Definitions()
+	if err != nil {
+		return err
+	}
+	definitions.Store(tables)
+	return nil
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright (c) 2020 Zachariah Knight <aeros.storkpk@gmail.com>
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted, provided that the above copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ *
+ */
+
+package world
+
+import (
+	"sort"
+
+	"github.com/spkaeros/rscgo/pkg/definitions"
+	rscRand "github.com/spkaeros/rscgo/pkg/rand"
+)
+
+//rollTable Rolls once on the provided weighted drops and shared rare tables, and returns the item it lands on, or
+// false if it lands on nothing.  Whatever weight the table leaves out of 1 is the chance of landing on nothing.
+func rollTable(drops []definitions.Drop, rare map[int]float64) (definitions.Drop, bool) {
+	// main drops are keyed by their index, nothing by the index after them, and the rare tables by the indexes after that
+	nothing := len(drops)
+	options := make(IntProbabilitys, len(drops)+len(rare)+1)
+	total := 0.0
+	for i, drop := range drops {
+		options[i] = drop.Weight
+		total += drop.Weight
+	}
+	rareTables := make([]int, 0, len(rare))
+	for id, weight := range rare {
+		options[nothing+1+len(rareTables)] = weight
+		rareTables = append(rareTables, id)
+		total += weight
+	}
+	if total < 1 {
+		options[nothing] = 1 - total
+	}
+
+	switch choice := WeightedChoice(options); {
+	case choice < 0 || choice == nothing:
+		return definitions.Drop{}, false
+	case choice < nothing:
+		return drops[choice], true
+	default:
+		return rollTable(definitions.RareDrops(rareTables[choice-nothing-1]), nil)
+	}
+}
+
+//dropAmount Returns a random amount of the provided drop, within its stack range.
+func dropAmount(drop definitions.Drop) int {
+	if drop.Max <= drop.Min {
+		return drop.Min
+	}
+	return drop.Min + rscRand.Intn(drop.Max-drop.Min+1)
+}
+
+//RollDrops Rolls for everything that a single kill of the NPC with the provided ID drops: its bones, anything else
+// it always drops, and whatever its main roll lands on.
+func RollDrops(npcID int) []*Item {
+	items := []*Item{{ID: DefaultDrop, Amount: 1}}
+	table := definitions.Drops(npcID)
+	if !table.Defined() {
+		return items
+	}
+	for _, drop := range table.Always {
+		items = append(items, &Item{ID: drop.ID, Amount: dropAmount(drop)})
+	}
+	if drop, ok := rollTable(table.Main, table.Rare); ok {
+		items = append(items, &Item{ID: drop.ID, Amount: dropAmount(drop)})
+	}
+	return items
+}
+
+//DropStats How often a single item was dropped over a run of simulated kills, and how many of it were dropped altogether.
+type DropStats struct {
+	ID     int
+	Drops  int
+	Amount int
+}
+
+//SimulateDrops Rolls the drops of the NPC with the provided ID for the provided number of kills, and returns how often
+// each item was dropped, most often dropped first.
+func SimulateDrops(npcID, kills int) []DropStats {
+	counts := make(map[int]*DropStats)
+	for i := 0; i < kills; i++ {
+		for _, item := range RollDrops(npcID) {
+			stats, ok := counts[item.ID]
+			if !ok {
+				stats = &DropStats{ID: item.ID}
+				counts[item.ID] = stats
+			}
+			stats.Drops++
+			stats.Amount += item.Amount
+		}
+	}
+
+	results := make([]DropStats, 0, len(counts))
+	for _, stats := range counts {
+		results = append(results, *stats)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Drops == results[j].Drops {
+			return results[i].ID < results[j].ID
+		}
+		return results[i].Drops > results[j].Drops
+	})
+	return results
+}
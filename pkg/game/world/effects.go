@@ -0,0 +1,239 @@
+/*
+ * Copyright (c) 2020 Zachariah Knight <aeros.storkpk@gmail.com>
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted, provided that the above copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ *
+ */
+
+package world
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/spkaeros/rscgo/pkg/game/entity"
+	"github.com/spkaeros/rscgo/pkg/tasks"
+)
+
+//poisonInterval How many game ticks pass between each hit of poison damage.
+const poisonInterval = 30
+
+//poisonChance The percent chance that a hit landed by something poisonous poisons its target.
+const poisonChance = 25.0
+
+//PoisonWeapons Maps the item ID of every poisoned weapon and piece of ammunition to the strength of the poison that
+// its hits can cause.
+var PoisonWeapons = map[int]int{
+	560: 10, 559: 10, 561: 10, 565: 10, 562: 10, 564: 10, 563: 10, // Daggers
+	574: 10, 639: 10, 641: 10, 643: 10, 645: 10, 647: 10, // Arrows
+	592:  10,                     // Crossbow bolts
+	1122: 10, 1123: 10, 1124: 10, // Throwing darts
+}
+
+//PoisonNpcs Maps the ID of every poisonous NPC to the strength of the poison that its hits can cause.
+var PoisonNpcs = map[int]int{
+	99:  20, // Deadly Red spider
+	271: 15, // Poison Scorpion
+	292: 30, // Poison Spider
+	521: 20, // Jungle Spider
+}
+
+//effectAttrs Returns the attributes that hold the effects on a mob.  A players effects are kept with the rest of its
+// persistent attributes, so that they carry on after logging back in, while NPCs only keep theirs until they respawn.
+func effectAttrs(m entity.MobileEntity) *entity.AttributeList {
+	if p := AsPlayer(m); p != nil {
+		return p.Attributes
+	}
+	return m.SessionCache()
+}
+
+//effectsActive Returns true if the effects on the mob should keep wearing on, which stops once it dies, leaves the
+// world or logs out.
+func effectsActive(m entity.MobileEntity) bool {
+	if m.Skills().Current(entity.StatHits) <= 0 {
+		return false
+	}
+	if p := AsPlayer(m); p != nil {
+		return p.Connected()
+	}
+	return !m.SessionCache().VarBool("removed", false)
+}
+
+//Poisoned Returns the strength of the poison on the mob, or 0 if it is not poisoned.
+func Poisoned(m entity.MobileEntity) int {
+	return effectAttrs(m).VarInt("poison", 0)
+}
+
+//PoisonImmune Returns true if the mob was recently cured of poison, and can not be poisoned again yet.
+func PoisonImmune(m entity.MobileEntity) bool {
+	return effectAttrs(m).VarTime("poisonImmunityTimer").After(time.Now())
+}
+
+//Poison Poisons target with poison of the provided strength, on behalf of source, which is credited with any kill that
+// the poison makes.  Each hit of poison damage does a fifth of its strength, rounded up, and weakens it by 1.
+// Mobs that are immune to poison, or already poisoned more strongly, are left alone.
+// Returns true if the target was poisoned.
+func Poison(target, source entity.MobileEntity, strength int) bool {
+	if strength <= 0 || PoisonImmune(target) || Poisoned(target) >= strength {
+		return false
+	}
+	effectAttrs(target).SetVar("poison", strength)
+	if source != nil {
+		target.SessionCache().SetVar("poisonedBy", source)
+	}
+	if p := AsPlayer(target); p != nil {
+		p.Message("@gr3@You have been poisoned!")
+	}
+	startPoison(target)
+	return true
+}
+
+//CurePoison Cures target of any poison, and makes it immune to poison for the provided number of game ticks.
+func CurePoison(target entity.MobileEntity, immunityTicks int) {
+	attrs := effectAttrs(target)
+	if attrs.VarInt("poison", 0) > 0 {
+		if p := AsPlayer(target); p != nil {
+			p.Message("You are no longer poisoned")
+		}
+	}
+	attrs.UnsetVar("poison")
+	target.SessionCache().UnsetVar("poisonedBy")
+	if immunityTicks > 0 {
+		attrs.SetVar("poisonImmunityTimer", time.Now().Add(TickMillis*time.Duration(immunityTicks)))
+	}
+}
+
+//startPoison Makes the poison on target start dealing its damage, unless it already is.
+func startPoison(target entity.MobileEntity) {
+	cache := target.SessionCache()
+	if cache.VarBool("poisonTicking", false) {
+		return
+	}
+	cache.SetVar("poisonTicking", true)
+	tasks.Schedule(poisonInterval, func() bool {
+		strength := Poisoned(target)
+		if strength <= 0 || !effectsActive(target) {
+			cache.UnsetVar("poisonTicking")
+			return true
+		}
+		if strength > 1 {
+			effectAttrs(target).SetVar("poison", strength-1)
+		} else {
+			CurePoison(target, 0)
+		}
+		if target.DamageFrom(cache.VarMob("poisonedBy"), (strength+4)/5, DamagePoison) || strength <= 1 {
+			cache.UnsetVar("poisonTicking")
+			return true
+		}
+		return false
+	})
+}
+
+//inflictPoison Rolls for whether a hit that attacker landed on defender poisons it, when the attacker is a poisonous
+// NPC, or when weapon is the item ID of a poisoned weapon or piece of ammunition.
+func inflictPoison(attacker, defender entity.MobileEntity, weapon int) {
+	strength, ok := PoisonWeapons[weapon]
+	if n := AsNpc(attacker); n != nil {
+		strength, ok = PoisonNpcs[n.ID]
+	}
+	if ok && Chance(poisonChance) {
+		Poison(defender, attacker, strength)
+	}
+}
+
+//meleeHitEffects Applies the effects of a melee hit that attacker landed on defender.
+func meleeHitEffects(attacker, defender entity.MobileEntity) {
+	weapon := -1
+	if p := AsPlayer(attacker); p != nil {
+		for id := range PoisonWeapons {
+			if p.Inventory.Equipped(id) {
+				weapon = id
+				break
+			}
+		}
+	}
+	inflictPoison(attacker, defender, weapon)
+}
+
+//statEffectAttr Returns the name of the attribute holding when the effect on the stat with the provided ID wears off.
+// Its name ends in Timer, which has it saved as how long is left of it.
+func statEffectAttr(stat int) string {
+	return "stat" + strconv.Itoa(stat) + "EffectTimer"
+}
+
+//StatEffectActive Returns true if the current level of the mobs stat with the provided ID is being held away from its
+// maximum by a boost or drain that has not worn off yet.
+func StatEffectActive(m entity.MobileEntity, stat int) bool {
+	return effectAttrs(m).VarTime(statEffectAttr(stat)).After(time.Now())
+}
+
+//ModifyStat Sets the current level of the stat with the provided ID on target to level, boosting or draining it for
+// the provided number of game ticks, after which it returns to its maximum level.  Changing a stat again while it is
+// already boosted or drained starts the wait over.
+func ModifyStat(target entity.MobileEntity, stat, level, ticks int) {
+	if level < 0 {
+		level = 0
+	}
+	target.Skills().SetCur(stat, level)
+	if p := AsPlayer(target); p != nil {
+		p.SendStat(stat)
+	}
+	expires := time.Now().Add(TickMillis * time.Duration(ticks))
+	effectAttrs(target).SetVar(statEffectAttr(stat), expires)
+	scheduleStatEffect(target, stat, expires)
+}
+
+//RestoreStat Ends any boost or drain on the stat with the provided ID on target, putting it back to its maximum level.
+func RestoreStat(target entity.MobileEntity, stat int) {
+	effectAttrs(target).UnsetVar(statEffectAttr(stat))
+	if target.Skills().Current(stat) == target.Skills().Maximum(stat) {
+		return
+	}
+	target.Skills().SetCur(stat, target.Skills().Maximum(stat))
+	if p := AsPlayer(target); p != nil {
+		p.SendStat(stat)
+		p.Message("Your " + entity.SkillName(stat) + " level has returned to normal")
+	}
+}
+
+//scheduleStatEffect Puts the stat back to normal once expires passes, unless the effect on it was changed since.
+func scheduleStatEffect(target entity.MobileEntity, stat int, expires time.Time) {
+	tasks.Schedule(1, func() bool {
+		if !effectAttrs(target).VarTime(statEffectAttr(stat)).Equal(expires) || !effectsActive(target) {
+			// changed again, already restored, or waiting for the player to come back
+			return true
+		}
+		if time.Now().Before(expires) {
+			return false
+		}
+		RestoreStat(target, stat)
+		return true
+	})
+}
+
+//ClearEffects Ends every effect on the mob at once, without any messages, e.g when it dies.
+func ClearEffects(m entity.MobileEntity) {
+	attrs := effectAttrs(m)
+	attrs.UnsetVar("poison")
+	attrs.UnsetVar("poisonImmunityTimer")
+	m.SessionCache().UnsetVar("poisonedBy")
+	for stat := 0; stat < 18; stat++ {
+		attrs.UnsetVar(statEffectAttr(stat))
+	}
+}
+
+//resumeEffects Picks the effects on a player back up where they left off when it logged out.
+func resumeEffects(p *Player) {
+	if Poisoned(p) > 0 {
+		startPoison(p)
+	}
+	for stat := 0; stat < 18; stat++ {
+		expires := p.Attributes.VarTime(statEffectAttr(stat))
+		if expires.IsZero() {
+			continue
+		}
+		scheduleStatEffect(p, stat, expires)
+	}
+}
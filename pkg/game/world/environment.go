@@ -54,16 +54,21 @@ func init() {
 		"Banking":				  reflect.ValueOf(StateBanking),
 		"Shopping":				  reflect.ValueOf(StateShopping),
 		"Batching":				  reflect.ValueOf(MSBatching),
+		"Sleeping":				  reflect.ValueOf(StateSleeping),
 	}
 	env.Packages["world"] = map[string]reflect.Value{
 		"getPlayer":              reflect.ValueOf(Players.FindIndex),
 		"OrderedDirections":              reflect.ValueOf(OrderedDirections),
 		"getPlayerByName":        reflect.ValueOf(Players.FindHash),
 		"getNpcNear":             reflect.ValueOf(NpcNearest),
+		"getNpcsWatching":        reflect.ValueOf(NpcsWatching),
 		"getGridNpc":             reflect.ValueOf(NpcVisibleFrom),
 		"players":                reflect.ValueOf(Players),
 		"getEquipmentDefinition": reflect.ValueOf(definitions.Equip),
 		"replaceObject":          reflect.ValueOf(ReplaceObject),
+		"replaceObjectFor":       reflect.ValueOf(ReplaceObjectFor),
+		"addObjectFor":           reflect.ValueOf(AddObjectFor),
+		"lineOfSight":            reflect.ValueOf(LineOfSight),
 		"addObject":              reflect.ValueOf(AddObject),
 		"removeObject":           reflect.ValueOf(RemoveObject),
 		"addNpc":                 reflect.ValueOf(AddNpc),
@@ -79,6 +84,35 @@ func init() {
 		"getNpc":                 reflect.ValueOf(GetNpc),
 		"attackNpcCalls":         reflect.ValueOf(NpcAtkTriggers),
 		"checkCollisions":        reflect.ValueOf(IsTileBlocking),
+		"placeSpawn":             reflect.ValueOf(PlaceSpawn),
+		"replaceSpawn":           reflect.ValueOf(ReplaceSpawn),
+		"deleteSpawn":            reflect.ValueOf(DeleteSpawn),
+		"undoEdit":               reflect.ValueOf(UndoEdit),
+		"objectSpawn":            reflect.ValueOf(ObjectSpawn),
+		"npcSpawn":               reflect.ValueOf(NpcSpawn),
+		"itemSpawn":              reflect.ValueOf(ItemSpawn),
+		"newObjectSpawn":         reflect.ValueOf(NewObjectSpawn),
+		"newNpcSpawn":            reflect.ValueOf(NewNpcSpawn),
+		"newItemSpawn":           reflect.ValueOf(NewItemSpawn),
+		"landscapeData":          reflect.ValueOf(LandscapeData),
+		"setTileOverlay":         reflect.ValueOf(SetTileOverlay),
+		"setTileElevation":       reflect.ValueOf(SetTileElevation),
+		"setHorizontalWall":      reflect.ValueOf(SetHorizontalWall),
+		"setVerticalWall":        reflect.ValueOf(SetVerticalWall),
+		"setDiagonalWall":        reflect.ValueOf(SetDiagonalWall),
+		"exportLandscape":        reflect.ValueOf(ExportLandscape),
+		"reloadDefinitions":      reflect.ValueOf(ReloadDefinitions),
+		"saveSnapshot":           reflect.ValueOf(SaveSnapshot),
+		"simulateDrops":          reflect.ValueOf(SimulateDrops),
+		"zoneAt":                 reflect.ValueOf(ZoneAt),
+		"zones":                  reflect.ValueOf(Zones),
+		"poison":                 reflect.ValueOf(Poison),
+		"curePoison":             reflect.ValueOf(CurePoison),
+		"poisoned":               reflect.ValueOf(Poisoned),
+		"poisonImmune":           reflect.ValueOf(PoisonImmune),
+		"modifyStat":             reflect.ValueOf(ModifyStat),
+		"restoreStat":            reflect.ValueOf(RestoreStat),
+		"statEffectActive":       reflect.ValueOf(StatEffectActive),
 		"tileData":               reflect.ValueOf(CollisionData),
 		"kickPlayer": reflect.ValueOf(func(client *Player) {
 			client.Unregister()
@@ -103,6 +137,9 @@ func init() {
 						player.Unregister()
 					})
 					time.Sleep(2 * time.Second)
+					if err := SaveSnapshot(""); err != nil {
+						log.Warn("Could not save world snapshot:", err)
+					}
 					os.Exit(200)
 					return true
 				}
@@ -159,6 +196,7 @@ func init() {
 		"groundItem": reflect.TypeOf(&GroundItem{}),
 		"npc":        reflect.TypeOf(&NPC{}),
 		"location":   reflect.TypeOf(Location{}),
+		"zone":       reflect.TypeOf(&Zone{}),
 	}
 	env.Packages["packets"] = map[string]reflect.Value{
 		"ping": reflect.ValueOf(67),
@@ -181,6 +219,8 @@ func init() {
 		"boundaryAction2": reflect.ValueOf(14),
 		"invOnScene": reflect.ValueOf(115),
 		"invOnBoundary": reflect.ValueOf(161),
+		"invOnGroundItem": reflect.ValueOf(53),
+		"invOnItem": reflect.ValueOf(91),
 		"unequip": reflect.ValueOf(170),
 		"dropItem": reflect.ValueOf(246),
 		"recoverAccount": reflect.ValueOf(220),
@@ -220,6 +260,7 @@ func init() {
 		"prayerOff": reflect.ValueOf(254),
 		"walkRequest": reflect.ValueOf(187),
 		"walkAction": reflect.ValueOf(16),
+		"sleepWord": reflect.ValueOf(45),
 	}
 	env.Packages["ids"] = map[string]reflect.Value{
 		"COOKEDMEAT":               reflect.ValueOf(132),
@@ -238,9 +279,9 @@ func init() {
 		"RAW_PIKE":                 reflect.ValueOf(363),
 		"RAW_SARDINE":              reflect.ValueOf(354),
 		"RAW_HERRING":              reflect.ValueOf(361),
-		"RAW_BASS":                 reflect.ValueOf(550),
+		"RAW_BASS":                 reflect.ValueOf(554),
 		"RAW_MACKEREL":             reflect.ValueOf(552),
-		"RAW_COD":                  reflect.ValueOf(554),
+		"RAW_COD":                  reflect.ValueOf(550),
 		"RAW_LOBSTER":              reflect.ValueOf(372),
 		"RAW_SWORDFISH":            reflect.ValueOf(369),
 		"RAW_TUNA":                 reflect.ValueOf(366),
@@ -264,6 +305,23 @@ func init() {
 		"MITHRIL_PICKAXE":          reflect.ValueOf(1260),
 		"ADAM_PICKAXE":             reflect.ValueOf(1261),
 		"RUNE_PICKAXE":             reflect.ValueOf(1262),
+		"BRONZE_AXE":               reflect.ValueOf(87),
+		"IRON_AXE":                 reflect.ValueOf(12),
+		"STEEL_AXE":                reflect.ValueOf(88),
+		"BLACK_AXE":                reflect.ValueOf(428),
+		"MITHRIL_AXE":              reflect.ValueOf(203),
+		"ADAM_AXE":                 reflect.ValueOf(204),
+		"RUNE_AXE":                 reflect.ValueOf(405),
+		"LOGS":                     reflect.ValueOf(14),
+		"OAK_LOGS":                 reflect.ValueOf(632),
+		"WILLOW_LOGS":              reflect.ValueOf(633),
+		"MAPLE_LOGS":               reflect.ValueOf(634),
+		"YEW_LOGS":                 reflect.ValueOf(635),
+		"MAGIC_LOGS":               reflect.ValueOf(636),
+		"TINDERBOX":                reflect.ValueOf(166),
+		"ASHES":                    reflect.ValueOf(181),
+		"COOKING_GAUNTLETS":        reflect.ValueOf(700),
+		"HAMMER":                   reflect.ValueOf(168),
 		"TIN_ORE":                  reflect.ValueOf(202),
 		"SLEEPING_BAG":             reflect.ValueOf(1263),
 		"NEEDLE":                   reflect.ValueOf(39),
@@ -331,6 +389,9 @@ func init() {
 		"GNOME_BALL":               reflect.ValueOf(981),
 		"BLURITE_ORE":              reflect.ValueOf(266),
 		"CLAY":                     reflect.ValueOf(149),
+		"SOFT_CLAY":                reflect.ValueOf(243),
+		"BUCKET":                   reflect.ValueOf(21),
+		"BUCKET_OF_WATER":          reflect.ValueOf(50),
 		"COPPER_ORE":               reflect.ValueOf(150),
 		"IRON_ORE":                 reflect.ValueOf(151),
 		"GOLD":                     reflect.ValueOf(152),
@@ -354,9 +415,23 @@ func init() {
 		"invOnObject": reflect.ValueOf(func(fn func(player *Player, boundary *Object, item *Item) bool) {
 			InvOnObjectTriggers = append(InvOnObjectTriggers, fn)
 		}),
+		"invOnGroundItem": reflect.ValueOf(func(fn func(player *Player, groundItem *GroundItem, item *Item) bool) {
+			InvOnGroundItemTriggers = append(InvOnGroundItemTriggers, fn)
+		}),
+		"itemOnItem": reflect.ValueOf(func(pred func(*Item, *Item) bool, fn func(player *Player, item1, item2 *Item)) {
+			ItemOnItemTriggers = append(ItemOnItemTriggers, ItemOnItemTrigger{pred, fn})
+		}),
 		"object": reflect.ValueOf(func(pred func(*Object, int) bool, fn func(player *Player, object *Object, click int)) {
 			ObjectTriggers = append(ObjectTriggers, ObjectTrigger{pred, fn})
 		}),
+		"gathering": reflect.ValueOf(func(m map[string]interface{}) {
+			node, err := NewGatheringNode(m)
+			if err != nil {
+				log.Warn("Could not register gathering node:", err)
+				return
+			}
+			RegisterGatheringNode(node)
+		}),
 		"item": reflect.ValueOf(func(check func(item *Item) bool, fn func(player *Player, item *Item)) {
 			ItemTriggers = append(ItemTriggers, ItemTrigger{check, fn})
 		}),
@@ -366,6 +441,9 @@ func init() {
 		"npc": reflect.ValueOf(func(predicate func(npc *NPC) bool, fn func(player *Player, npc *NPC)) {
 			NpcTalkList = append(NpcTalkList, NpcTrigger{predicate, fn})
 		}),
+		"npcAction": reflect.ValueOf(func(predicate func(npc *NPC) bool, fn func(player *Player, npc *NPC)) {
+			NpcActionTriggers = append(NpcActionTriggers, NpcTrigger{predicate, fn})
+		}),
 		"spell": reflect.ValueOf(func(ident interface{}, fn func(player *Player, spell interface{})) {
 			switch ident.(type) {
 			case int64:
@@ -378,9 +456,12 @@ func init() {
 		}),
 		"commands": reflect.ValueOf(CommandHandlers),
 		"chatNpcs": reflect.ValueOf(&NpcTalkList),
+		"npcActions": reflect.ValueOf(&NpcActionTriggers),
 		"sceneActions": reflect.ValueOf(&ObjectTriggers),
 		"invSceneActions": reflect.ValueOf(&InvOnObjectTriggers),
 		"invBoundaryActions": reflect.ValueOf(&InvOnBoundaryTriggers),
+		"invGroundItemActions": reflect.ValueOf(&InvOnGroundItemTriggers),
+		"itemOnItemActions": reflect.ValueOf(&ItemOnItemTriggers),
 		"boundaryActions": reflect.ValueOf(&BoundaryTriggers),
 		"spells": reflect.ValueOf(SpellTriggers),
 		"packet": reflect.ValueOf(func(ident interface{}, fn func(player *Player, packet interface{})) {
@@ -463,26 +544,29 @@ func ScriptEnv() *env.Env {
 	e.Define("HERBLAW", entity.StatHerblaw)
 	e.Define("AGILITY", entity.StatAgility)
 	e.Define("THIEVING", entity.StatThieving)
-	e.Define("PRAYER_THICK_SKIN", 0)
-	e.Define("PRAYER_BURST_OF_STRENGTH", 1)
-	e.Define("PRAYER_CLARITY_OF_THOUGHT", 2)
-	e.Define("PRAYER_ROCK_SKIN", 3)
-	e.Define("PRAYER_SUPERHUMAN_STRENGTH", 4)
-	e.Define("PRAYER_IMPROVED_REFLEXES", 5)
-	e.Define("PRAYER_RAPID_RESTORE", 6)
-	e.Define("PRAYER_RAPID_HEAL", 7)
-	e.Define("PRAYER_PROTECT_ITEM", 8)
-	e.Define("PRAYER_STEEL_SKIN", 9)
-	e.Define("PRAYER_ULTIMATE_STRENGTH", 10)
-	e.Define("PRAYER_INCREDIBLE_REFLEXES", 11)
-	e.Define("PRAYER_PARALYZE_MONSTER", 12)
-	e.Define("PRAYER_PROTECT_FROM_MISSILES", 13)
+	e.Define("PRAYER_THICK_SKIN", PrayerThickSkin)
+	e.Define("PRAYER_BURST_OF_STRENGTH", PrayerBurstOfStrength)
+	e.Define("PRAYER_CLARITY_OF_THOUGHT", PrayerClarityOfThought)
+	e.Define("PRAYER_ROCK_SKIN", PrayerRockSkin)
+	e.Define("PRAYER_SUPERHUMAN_STRENGTH", PrayerSuperhumanStrength)
+	e.Define("PRAYER_IMPROVED_REFLEXES", PrayerImprovedReflexes)
+	e.Define("PRAYER_RAPID_RESTORE", PrayerRapidRestore)
+	e.Define("PRAYER_RAPID_HEAL", PrayerRapidHeal)
+	e.Define("PRAYER_PROTECT_ITEM", PrayerProtectItem)
+	e.Define("PRAYER_STEEL_SKIN", PrayerSteelSkin)
+	e.Define("PRAYER_ULTIMATE_STRENGTH", PrayerUltimateStrength)
+	e.Define("PRAYER_INCREDIBLE_REFLEXES", PrayerIncredibleReflexes)
+	e.Define("PRAYER_PARALYZE_MONSTER", PrayerParalyzeMonster)
+	e.Define("PRAYER_PROTECT_FROM_MISSILES", PrayerProtectFromMissiles)
+	e.Define("DAMAGE_MELEE", DamageMelee)
+	e.Define("DAMAGE_MAGIC", DamageMagic)
+	e.Define("DAMAGE_RANGED", DamageRanged)
+	e.Define("DAMAGE_POISON", DamagePoison)
 	e.Define("ZeroTime", time.Time{})
-	e.Define("itemDefs", definitions.Items)
-	e.Define("objectDefs", definitions.ScenaryObjects)
+	e.Define("itemDef", definitions.Item)
 	e.Define("objectDef", definitions.Scenary)
-	e.Define("boundaryDefs", definitions.BoundaryObjects)
-	e.Define("npcDefs", definitions.Npcs)
+	e.Define("boundaryDef", definitions.Boundary)
+	e.Define("npcDef", definitions.Npc)
 	e.Define("lvlToExp", entity.LevelToExperience)
 	e.Define("expToLvl", entity.ExperienceToLevel)
 	e.Define("withinWorld", WithinWorld)
@@ -522,6 +606,12 @@ func ScriptEnv() *env.Env {
 	e.Define("EAST", East)
 	e.Define("WEST", West)
 	e.Define("parseDirection", ParseDirection)
+	e.Define("ZONE_PVP", ZonePvp)
+	e.Define("ZONE_MULTI", ZoneMulti)
+	e.Define("ZONE_NO_TELEPORT", ZoneNoTeleport)
+	e.Define("ZONE_SAFE_DEATH", ZoneSafeDeath)
+	e.Define("ZONE_MEMBERS", ZoneMembers)
+	e.Define("MAX_FATIGUE", MaxFatigue)
 	e.Define("contains", func(s []int64, elem int64) bool {
 		for _, v := range s {
 			if v == elem {
@@ -530,12 +620,7 @@ func ScriptEnv() *env.Env {
 		}
 		return false
 	})
-	e.Define("gatheringSuccess", func(req, cur int) bool {
-		if cur < req {
-			return false
-		}
-		return rand.Rng.Float64()*127.0+1.0 <= float64(cur)+40.0-float64(req)*1.5
-	})
+	e.Define("gatheringSuccess", GatheringSuccess)
 	e.Define("roll", Chance)
 	e.Define("parseArgs", strutil.ParseArgs)
 	e.Define("boundedRoll", BoundedChance)
@@ -590,8 +675,8 @@ func ScriptEnv() *env.Env {
 	})
 
 	e.Define("fuzzyItem", func(input string) (itemList []map[string]interface{}) {
-		for id, item := range definitions.Items {
-			if fuzzy.MatchNormalized(strings.ToLower(input), strings.ToLower(item.Name)) {
+		for id, item := range definitions.Current().Items {
+			if item.Defined() && fuzzy.MatchNormalized(strings.ToLower(input), strings.ToLower(item.Name)) {
 				 rank := fuzzy.LevenshteinDistance(input, item.Name)
 				itemList = append(itemList, map[string]interface{}{"name": item.Name, "id": id, "rank": rank})
 				for idx := len(itemList)-1; idx > 0; idx-- {
@@ -609,33 +694,27 @@ func ScriptEnv() *env.Env {
 	e.Define("itemPredicate", func(ids ...interface{}) func(*Item) bool {
 		return func(item *Item) bool {
 			for _, id := range ids {
-				switch id.(type) {
-				case string:
-					if item.Command() == id.(string) || item.Name() == id.(string) {
-						return true
-					}
-				case int64:
-					if item.ID == int(id.(int64)) {
-						return true
-					}
-				case int:
-					if item.ID == id.(int) {
-						return true
-					}
-				default:
-					break
+				if itemMatches(item, id) {
+					return true
 				}
 			}
 			return false
 		}
 	})
+	e.Define("itemOnItemPredicate", func(first, second interface{}) func(*Item, *Item) bool {
+		return func(item1, item2 *Item) bool {
+			// the client sends the items in whichever order the player used them in
+			return itemMatches(item1, first) && itemMatches(item2, second) ||
+				itemMatches(item1, second) && itemMatches(item2, first)
+		}
+	})
 	e.Define("objectPredicate", func(ids ...interface{}) func(*Object, int) bool {
 		return func(object *Object, click int) bool {
 			for _, id := range ids {
 				switch id.(type) {
 				case string:
-					if definitions.ScenaryObjects[object.ID].Commands[click] == id.(string) ||
-							definitions.ScenaryObjects[object.ID].Name == id.(string) {
+					if definitions.Scenary(object.ID).Commands[click] == id.(string) ||
+							definitions.Scenary(object.ID).Name == id.(string) {
 						return true
 					}
 				case int64:
@@ -658,6 +737,26 @@ func ScriptEnv() *env.Env {
 	e = core.Import(e)
 	return e
 }
+
+//itemMatches Returns true if item is described by id, which is an item ID, a command or name, or a list of those.
+func itemMatches(item *Item, id interface{}) bool {
+	switch id := id.(type) {
+	case string:
+		return item.Command() == id || item.Name() == id
+	case int64:
+		return item.ID == int(id)
+	case int:
+		return item.ID == id
+	case []interface{}:
+		for _, v := range id {
+			if itemMatches(item, v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func init() {
 	CommandHandlers["shutdown"] = func(player *Player, args []string) {
 		wait := sync.WaitGroup{}
@@ -672,6 +771,9 @@ func init() {
 			}()
 		})
 		wait.Wait()
+		if err := SaveSnapshot(""); err != nil {
+			log.Warn("Could not save world snapshot:", err)
+		}
 		os.Exit(1)
 	}
 	CommandHandlers["memdump"] = func(player *Player, args []string) {
@@ -0,0 +1,155 @@
+/*
+ * Copyright (c) 2020 Zachariah Knight <aeros.storkpk@gmail.com>
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted, provided that the above copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ *
+ */
+
+package world
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/spkaeros/rscgo/pkg/game/entity"
+)
+
+//Combatant Everything about a mob that a CombatFormula looks at.  Every mob in the game is one, and the combat
+// simulator builds its own out of loadouts, without needing a world to put them in.
+type Combatant interface {
+	Skills() *entity.SkillTable
+	PrayerActivated(int) bool
+	FightMode() int
+	AimPoints() int
+	PowerPoints() int
+	ArmourPoints() int
+	MagicPoints() int
+	RangedPoints() int
+	CombatRng() *rand.Rand
+}
+
+//CombatFormula Decides how accurate mobs are, how hard they hit, and how likely each attack is to land.
+type CombatFormula interface {
+	//PrayerModifiers Returns the percentage that the combatants active prayers boost its attack, defense and strength by.
+	PrayerModifiers(c Combatant) [3]int
+	//StyleBonus Returns the invisible levels that the combatants fight mode adds to the stat with the provided ID.
+	StyleBonus(c Combatant, stat int) int
+	//AttackPoints Returns the melee accuracy of the combatant.
+	AttackPoints(c Combatant) float64
+	//DefensePoints Returns the defensive capability of the combatant, against every kind of attack.
+	DefensePoints(c Combatant) float64
+	//MaxMeleeDamage Returns the melee max hit of the combatant.
+	MaxMeleeDamage(c Combatant) float64
+	//RangedAccuracy Returns the ranged accuracy of the combatant, on the same scale as AttackPoints.
+	RangedAccuracy(c Combatant) float64
+	//MaxRangedDamage Returns the max hit of the combatant when firing ammunition of the provided power.
+	MaxRangedDamage(c Combatant, ammoPower int) float64
+	//HitChance Returns the chance, out of 1, that an attack with the provided accuracy lands on a target with the
+	// provided defense.
+	HitChance(accuracy, defense float64) float64
+	//GenerateHit Returns a random amount of damage between 1 and max for an attack that landed, using rng.
+	GenerateHit(rng *rand.Rand, max float64) int
+}
+
+//DefaultCombatFormula The combat formula that every fight in the game uses.  Replace it before the game starts to
+// change how combat works.
+var DefaultCombatFormula CombatFormula = ClassicFormula{}
+
+//ClassicFormula The combat formula RSCGo has always used.  Embed it to change only part of it.
+type ClassicFormula struct{}
+
+func (ClassicFormula) PrayerModifiers(c Combatant) (modifiers [3]int) {
+	// how much each tier of prayers affects combat, by percentage
+	power := [3]int{5, 10, 15}
+
+	// combat prayers ordered as: attack, defense, strength
+	// same as stat panel order; this makes the array index match
+	prayers := [...][3]int{
+		{2, 5, 11},
+		{0, 3, 9},
+		{1, 4, 10},
+	}
+	for skillIdx, modifierList := range prayers {
+		for tier, prayer := range modifierList {
+			if c.PrayerActivated(prayer) {
+				modifiers[skillIdx] = power[tier]
+			}
+		}
+	}
+	return modifiers
+}
+
+func (ClassicFormula) StyleBonus(c Combatant, stat int) int {
+	mode := c.FightMode()
+	if mode == 0 {
+		return 1
+	}
+	if mode == (stat+1)%3+1 {
+		return 3
+	}
+	return 0
+}
+
+//effectiveLevel Returns the combatants current level of the provided combat stat, boosted by its prayers and fight mode.
+func (f ClassicFormula) effectiveLevel(c Combatant, stat int) float64 {
+	return float64(c.Skills().Current(stat))*(float64(f.PrayerModifiers(c)[stat])/100+1) + float64(f.StyleBonus(c, stat))
+}
+
+//scale Returns level scaled up by the provided equipment bonus points.
+func scale(level float64, points int) float64 {
+	return math.Ceil(level * (float64(points)*0.00175 + 0.1))
+}
+
+func (f ClassicFormula) AttackPoints(c Combatant) float64 {
+	return scale(f.effectiveLevel(c, entity.StatAttack), c.AimPoints())
+}
+
+func (f ClassicFormula) DefensePoints(c Combatant) float64 {
+	return scale(f.effectiveLevel(c, entity.StatDefense), c.ArmourPoints())
+}
+
+func (f ClassicFormula) MaxMeleeDamage(c Combatant) float64 {
+	return scale(f.effectiveLevel(c, entity.StatStrength), c.PowerPoints())
+}
+
+func (ClassicFormula) RangedAccuracy(c Combatant) float64 {
+	return scale(float64(c.Skills().Current(entity.StatRanged)), c.RangedPoints())
+}
+
+func (ClassicFormula) MaxRangedDamage(c Combatant, ammoPower int) float64 {
+	return scale(float64(c.Skills().Current(entity.StatRanged)), ammoPower)
+}
+
+//HitChance The accuracy is compared to 4 times the defense, and scaled onto a byte that a random byte must not go over,
+// which is capped at 212, so that no attack lands more than 83% of the time.
+func (ClassicFormula) HitChance(accuracy, defense float64) float64 {
+	threshold := int(math.Max(0.0, math.Min(212.0, 256.0*accuracy/(defense*4.0))))
+	return float64(threshold+1) / 256.0
+}
+
+//GenerateHit returns a normally distributed random number between 1 and max, inclusive.  This is widely believed to
+// be how Jagex generated damage hits, and it feels accurate while playing.
+func (ClassicFormula) GenerateHit(rng *rand.Rand, max float64) int {
+	mean := max / 2.0
+	value := 0.0
+	for tries := 0; tries < 25; tries++ {
+		value = math.Floor(mean + rng.NormFloat64()*(max/3.0))
+		if value >= 1.0 && value <= max {
+			return int(value)
+		}
+	}
+	// after 25 out of bounds values, we just clamp whatever value we do have into bounds
+	return int(math.Max(1, math.Min(max, value)))
+}
+
+//RollHit Rolls whether an attack from attacker with the provided accuracy lands on a target with the provided defense,
+// and returns the damage it does, up to max, or 0 if it misses.  Both rolls use the attackers combat PRNG.
+func RollHit(attacker Combatant, accuracy, defense, max float64) int {
+	rng := attacker.CombatRng()
+	if rng.Float64() < DefaultCombatFormula.HitChance(accuracy, defense) {
+		return DefaultCombatFormula.GenerateHit(rng, max)
+	}
+	return 0
+}
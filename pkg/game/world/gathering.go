@@ -0,0 +1,297 @@
+/*
+ * Copyright (c) 2020 Zachariah Knight <aeros.storkpk@gmail.com>
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted, provided that the above copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ *
+ */
+
+package world
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spkaeros/rscgo/pkg/definitions"
+	rscRand "github.com/spkaeros/rscgo/pkg/rand"
+)
+
+//GatheringTool An item that players can gather from a resource node with, e.g a pickaxe or a harpoon.
+type GatheringTool struct {
+	// ID is the item ID of the tool.
+	ID int `json:"id"`
+	// Level is the skill level needed to use the tool.
+	Level int `json:"lvl"`
+	// Bonus is added to the players skill level when rolling to see if it gathered anything with the tool.
+	Bonus int `json:"bonus"`
+}
+
+//GatheringProduct An item that players can get from a resource node.
+type GatheringProduct struct {
+	// ID is the item ID of the product.
+	ID int `json:"id"`
+	// Level is the skill level needed to get the product.
+	Level int `json:"lvl"`
+	// Exp is the experience given for getting the product.
+	Exp int `json:"exp"`
+	// Weight is how likely the product is to be picked, out of every product that the player has the level for.
+	Weight float64 `json:"weight"`
+}
+
+//GatheringNode Describes a type of resource node that players gather items from with a gathering skill, e.g copper
+// rocks for mining, or the net option on a fishing spot for fishing.
+type GatheringNode struct {
+	// Skill is the ID of the skill that the node needs and trains.
+	Skill int `json:"skill"`
+	// Objects are the IDs of the scene objects that make up the node.
+	Objects []int `json:"objects"`
+	// Click is which of the objects commands gathers from the node.
+	Click int `json:"click"`
+	// Tools are the items that can be used to gather from the node; the best one that the player has the level for is
+	// used.  When left empty, no tool is needed.
+	Tools []GatheringTool `json:"tools"`
+	// Bait is the ID of an item used up every time that something is gathered, or -1 for none.
+	Bait int `json:"bait"`
+	// Products are the items that the node gives.
+	Products []GatheringProduct `json:"products"`
+	// Deplete is the percent chance that the node runs out after each product that it gives.
+	Deplete float64 `json:"deplete"`
+	// Depleted is the ID of the scene object that replaces the node while it is run out.
+	Depleted int `json:"depleted"`
+	// Respawn is how many game ticks the node stays run out for.
+	Respawn int `json:"respawn"`
+	// Fatigue is how much fatigue is added for each point of experience gained from the node.
+	Fatigue int `json:"fatigue"`
+	// FatigueLimit is how much fatigue makes players too tired to gather from the node.
+	FatigueLimit int `json:"fatigueLimit"`
+	// Ticks is how many game ticks each attempt at gathering from the node takes.
+	Ticks int `json:"ticks"`
+	// Batch is true if players keep gathering from the node until they can not, and false to only try once.
+	Batch bool `json:"batch"`
+	// Sound is the name of the sound that plays with each attempt.
+	Sound string `json:"sound"`
+	// Messages are sent to the player as things happen while it gathers from the node, keyed by event.  See
+	// gatheringMessages for the events and their defaults.
+	Messages map[string]string `json:"messages"`
+	// Level is the lowest level that any of the products needs.
+	Level int `json:"-"`
+}
+
+//gatheringMessages The messages sent when a gathering node leaves one out.  Where a message has a formatting verb,
+// it is filled in with: the tools name for noTool, the level needed for lvl, the bait name for noBait, and the
+// product name for success.
+var gatheringMessages = map[string]string{
+	"lvl":     "You need a level of %d to do that",
+	"noTool":  "You need a %s to do that",
+	"noBait":  "You don't have any %s left",
+	"tired":   "You are too tired to do that",
+	"full":    "Your inventory is too full to hold any more",
+	"empty":   "There is nothing left to gather here",
+	"start":   "",
+	"success": "You get some %s",
+	"fail":    "You fail to get anything",
+}
+
+//RegisterGatheringNode Makes players able to gather from node, by adding an object trigger for it.
+func RegisterGatheringNode(node *GatheringNode) {
+	node.Level = -1
+	for _, product := range node.Products {
+		if node.Level < 0 || product.Level < node.Level {
+			node.Level = product.Level
+		}
+	}
+	ObjectTriggers = append(ObjectTriggers, ObjectTrigger{func(object *Object, click int) bool {
+		if click != node.Click {
+			return false
+		}
+		for _, id := range node.Objects {
+			if object.ID == id {
+				return true
+			}
+		}
+		return false
+	}, node.Gather})
+}
+
+//NewGatheringNode Builds a gathering node out of the script map m, whose keys are the JSON names of the fields of
+// GatheringNode.  Fields left out of m keep their defaults; 3 ticks, no bait, batching, a fatigue limit of MaxFatigue,
+// and the default messages.
+func NewGatheringNode(m map[string]interface{}) (*GatheringNode, error) {
+	node := &GatheringNode{Bait: -1, Ticks: 3, Batch: true, FatigueLimit: MaxFatigue}
+	// Script maps are keyed by interface{}, which encoding/json can't handle, so they are rekeyed by string first
+	data, err := json.Marshal(stringKeyed(m))
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, node); err != nil {
+		return nil, err
+	}
+	if len(node.Objects) == 0 || len(node.Products) == 0 {
+		return nil, fmt.Errorf("gathering node needs at least one object and one product")
+	}
+	return node, nil
+}
+
+//stringKeyed Returns v with every map inside of it keyed by the string form of its keys.
+func stringKeyed(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			m[fmt.Sprint(key)] = stringKeyed(val)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			m[key] = stringKeyed(val)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			s[i] = stringKeyed(val)
+		}
+		return s
+	}
+	return v
+}
+
+//message Sends the player the message for event, formatted with arg if it asks for it.  Events with empty messages
+// send nothing.
+func (n *GatheringNode) message(p *Player, event string, arg interface{}) {
+	msg, ok := n.Messages[event]
+	if !ok {
+		msg = gatheringMessages[event]
+	}
+	if len(msg) == 0 {
+		return
+	}
+	if strings.Contains(msg, "%") {
+		msg = fmt.Sprintf(msg, arg)
+	}
+	p.Message(msg)
+}
+
+//tool Returns the tool with the highest bonus that the player carries and has the level to use.
+// Returns false if it has none.
+func (n *GatheringNode) tool(p *Player) (best GatheringTool, ok bool) {
+	if len(n.Tools) == 0 {
+		return GatheringTool{ID: -1}, true
+	}
+	for _, tool := range n.Tools {
+		if (!ok || tool.Bonus > best.Bonus) && p.Skills().Current(n.Skill) >= tool.Level && p.Inventory.CountID(tool.ID) > 0 {
+			best, ok = tool, true
+		}
+	}
+	return
+}
+
+//product Returns a randomly picked product out of the ones that the provided level is high enough for, weighted by
+// their Weight.  If it is too low for all of them, returns the first.
+func (n *GatheringNode) product(level int) GatheringProduct {
+	choices := make(IntProbabilitys, len(n.Products))
+	for i, product := range n.Products {
+		if product.Level > level {
+			continue
+		}
+		choices[i] = product.Weight
+		if product.Weight <= 0 {
+			choices[i] = 1
+		}
+	}
+	if i := WeightedChoice(choices); i >= 0 {
+		return n.Products[i]
+	}
+	return n.Products[0]
+}
+
+//Gather Makes the player gather from object, an instance of the node, once or for as long as it can depending on Batch.
+func (n *GatheringNode) Gather(p *Player, object *Object, click int) {
+	if p.Skills().Current(n.Skill) < n.Level {
+		n.message(p, "lvl", n.Level)
+		return
+	}
+	tool, ok := n.tool(p)
+	if !ok {
+		// names the tool that is easiest to use
+		easiest := n.Tools[0]
+		for _, tool := range n.Tools {
+			if tool.Level < easiest.Level {
+				easiest = tool
+			}
+		}
+		n.message(p, "noTool", strings.ToLower(definitions.Item(easiest.ID).Name))
+		return
+	}
+	times := 0
+	if !n.Batch {
+		times = 1
+	}
+	gathered := false
+	p.Batch(n.Ticks, times, func() bool {
+		if GetObject(object.X(), object.Y()) != object {
+			// if the pointers don't match, the node ran out; once it was this player that ran it out, it already knows
+			if !gathered {
+				n.message(p, "empty", nil)
+			}
+			return false
+		}
+		if p.Fatigue() >= n.FatigueLimit {
+			n.message(p, "tired", nil)
+			return false
+		}
+		if n.Bait >= 0 && p.Inventory.CountID(n.Bait) <= 0 {
+			n.message(p, "noBait", strings.ToLower(definitions.Item(n.Bait).Name))
+			return false
+		}
+		if p.Inventory.Size() >= p.Inventory.Capacity {
+			n.message(p, "full", nil)
+			return false
+		}
+		if len(n.Sound) > 0 {
+			p.PlaySound(n.Sound)
+		}
+		if tool.ID >= 0 {
+			p.ItemBubble(tool.ID)
+		}
+		n.message(p, "start", nil)
+		return true
+	}, func() {
+		if GetObject(object.X(), object.Y()) != object {
+			// someone else ran the node out while this player was working on it; the next attempt lets it know
+			return
+		}
+		level := p.Skills().Current(n.Skill)
+		product := n.product(level)
+		if !GatheringSuccess(product.Level, level+tool.Bonus) {
+			n.message(p, "fail", nil)
+			return
+		}
+		if n.Bait >= 0 && p.Inventory.RemoveByID(n.Bait, 1) < 0 {
+			// the bait was dropped or used up while waiting on this attempt; the next one tells the player about it
+			return
+		}
+		n.message(p, "success", strings.ToLower(definitions.Item(product.ID).Name))
+		p.AddItem(product.ID, 1)
+		p.IncExp(n.Skill, product.Exp)
+		if n.Fatigue > 0 {
+			p.AddFatigue(product.Exp * n.Fatigue)
+		}
+		gathered = true
+		if n.Deplete > 0 && Chance(n.Deplete) {
+			ReplaceObjectFor(object, n.Depleted, n.Respawn)
+		}
+	})
+}
+
+//GatheringSuccess Returns true if a player with the skill level cur manages to gather something that needs the skill
+// level req.  The higher cur is over req, the more likely it is.
+func GatheringSuccess(req, cur int) bool {
+	if cur < req {
+		return false
+	}
+	return rscRand.Rng.Float64()*127.0+1.0 <= float64(cur)+40.0-float64(req)*1.5
+}
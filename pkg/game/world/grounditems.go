@@ -0,0 +1,123 @@
+/*
+ * Copyright (c) 2020 Zachariah Knight <aeros.storkpk@gmail.com>
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted, provided that the above copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ *
+ */
+
+package world
+
+import (
+	"sync"
+	"time"
+
+	"github.com/spkaeros/rscgo/pkg/config"
+	"github.com/spkaeros/rscgo/pkg/tasks"
+)
+
+//indexPool Hands out unique indexes, and reuses the ones that have been given back before making new ones.
+type indexPool struct {
+	sync.Mutex
+	free []int
+	next int
+}
+
+//Get Returns an index that is not in use.
+func (p *indexPool) Get() int {
+	p.Lock()
+	defer p.Unlock()
+	if n := len(p.free); n > 0 {
+		idx := p.free[n-1]
+		p.free = p.free[:n-1]
+		return idx
+	}
+	p.next++
+	return p.next - 1
+}
+
+//Put Gives an index back to the pool, to be handed out again.
+func (p *indexPool) Put(idx int) {
+	p.Lock()
+	defer p.Unlock()
+	p.free = append(p.free, idx)
+}
+
+//Size Returns how many indexes are in use.
+func (p *indexPool) Size() int {
+	p.Lock()
+	defer p.Unlock()
+	return p.next - len(p.free)
+}
+
+//respawningItems Persistent items that have been picked up, and the ticks that they will respawn on.
+var respawningItems = struct {
+	sync.Mutex
+	set map[*GroundItem]int
+}{set: make(map[*GroundItem]int)}
+
+//ItemIndexer Ensures unique indexes for ground items.  Items hold onto theirs from when they are first added to the
+// world until they are removed for good, so persistent items keep theirs while they wait to respawn.
+var ItemIndexer = &indexPool{}
+
+//respawnTicks Converts a respawn time in seconds, as ground item spawns are stored, into game ticks.
+func respawnTicks(seconds int) int {
+	return int(time.Duration(seconds) * time.Second / TickMillis)
+}
+
+//spawn Begins the lifecycle of a ground item that has just been added to the world.  Items with an Owner are private
+// for the configured number of ticks, and then public until they despawn; items without one start out public.
+// Persistent items are always public, and stay until they are picked up.
+//
+// Every stage is counted in game ticks, so the timers stop along with the tick loop if it is ever paused.
+func (i *GroundItem) spawn() {
+	if i.Index < 0 {
+		i.Index = ItemIndexer.Get()
+	}
+	i.SetVar("spawnTime", time.Now())
+	if i.VarBool("persistent", false) {
+		i.SetVar("visibility", 2)
+		return
+	}
+	// the age is kept with the item, so that items restored from a world snapshot pick up where they left off
+	if len(i.Owner) > 0 && i.VarInt("age", 0) < config.GroundItemPrivateTicks() {
+		i.SetVar("visibility", 1)
+	} else {
+		i.SetVar("visibility", 2)
+	}
+	tasks.TickList.Add(func() bool {
+		if i.Visibility() == 0 {
+			// picked up, or removed some other way
+			return true
+		}
+		i.Inc("age", 1)
+		age := i.VarInt("age", 0)
+		if i.Visibility() == 1 && age >= config.GroundItemPrivateTicks() {
+			// Time for everyone to see it!
+			i.SetVar("visibility", 2)
+		}
+		if age >= config.GroundItemPrivateTicks()+config.GroundItemPublicTicks() {
+			i.Remove()
+			return true
+		}
+		return false
+	})
+}
+
+//DropItem Adds an item dropped by a death to the world.  The item is private to owner at first, or public right away
+// when owner is nil, e.g when nobody is owed the loot.
+func DropItem(owner *Player, item *GroundItem) {
+	if owner != nil {
+		item.Owner = owner.Username()
+	}
+	AddItem(item)
+}
+
+//release Gives the item's index back for reuse, once it has been removed from the world for good.
+func (i *GroundItem) release() {
+	if i.Index >= 0 {
+		ItemIndexer.Put(i.Index)
+		i.Index = -1
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"sync"
 	"time"
 
-	"go.uber.org/atomic"
 
 	"github.com/spkaeros/rscgo/pkg/definitions"
 	"github.com/spkaeros/rscgo/pkg/errors"
@@ -40,10 +39,11 @@ func NewItem(id, amt, idx int, worn bool) *Item {
 
 //Name returns the receivers name
 func (i *Item) Name() string {
-	if i.ID >= len(definitions.Items) || i.ID < 0 {
+	def := definitions.Item(i.ID)
+	if !def.Defined() {
 		return "nil"
 	}
-	return definitions.Items[i.ID].Name
+	return def.Name
 }
 
 //Price type alias for item prices
@@ -51,10 +51,11 @@ type Price int
 
 //Price returns the receivers base price
 func (i *Item) Price() Price {
-	if i.ID >= len(definitions.Items) || i.ID < 0 {
+	def := definitions.Item(i.ID)
+	if !def.Defined() {
 		return -1
 	}
-	return Price(definitions.Items[i.ID].BasePrice)
+	return Price(def.BasePrice)
 }
 
 //DeltaAmount returns the difference between the amount of o and the amount of the receiver
@@ -93,15 +94,16 @@ func (p Price) Scale(percent int) Price {
 
 //Command Returns the item command, or nil if none
 func (i *Item) Command() string {
-	if i.ID >= len(definitions.Items) || i.ID < 0 {
+	def := definitions.Item(i.ID)
+	if !def.Defined() {
 		return "nil"
 	}
-	return definitions.Items[i.ID].Command
+	return def.Command
 }
 
 //WieldPos Returns the item equip slot, or -1 if none
 func (i *Item) WieldPos() int {
-	if i.ID >= len(definitions.Items) || i.ID < 0 {
+	if !definitions.Item(i.ID).Defined() {
 		return -1
 	}
 	def := definitions.Equip(i.ID)
@@ -113,10 +115,11 @@ func (i *Item) WieldPos() int {
 
 //Stackable Returns true if the item is stackable, false otherwise.
 func (i *Item) Stackable() bool {
-	if i.ID >= len(definitions.Items) || i.ID < 0 {
+	def := definitions.Item(i.ID)
+	if !def.Defined() {
 		return false
 	}
-	return definitions.Items[i.ID].Stackable
+	return def.Stackable
 }
 
 //GroundItem Represents a single ground item within the game.
@@ -127,91 +130,47 @@ type GroundItem struct {
 	Entity
 }
 
-//ItemIndexer Ensures unique indexes for ground items.
-//  TODO: Proper indexing
-var ItemIndexer = atomic.NewUint32(0)
-
 //Visibility This is a special state attribute to indicate who the receiver item is visible to.
 // Value 0 means the item has expired and is no longer visible to anybody.
 //
 // Value 1 means the item is visible to only the Owner of it and game administrators(rank=2), e.g if you kill someone or something,
-// this will be the value for the first minute or two after it is created, and then will change to value 2.
-// NOTE: If this is the current value, but the belongsTo attribute is not set e.g nobody owns this item, it will update
-// itself to value 2 prior to when it normally would.
+// this will be the value for the first minute or so after it is created, and then will change to value 2.
+// NOTE: If the item has no Owner when it is added to the world, it skips this value and starts at value 2.
 //
 // Value 2 means the item is visible to all players.  This is the value when e.g the game starts and makes the worlds
 // default item spawns, or an NPC kills a player and they drop their items and/or bones...This is the state that most
 // transient ground items will likely spend the most time in before unsetting the visibility attribute(same as value=0)
 // and thus disappearing.
+//
+// How long each value lasts is set in the ground_items section of the server config, and counted in game ticks.
 func (i *GroundItem) Visibility() int {
 	return i.VarInt("visibility", 0)
 }
 
-//SpawnedTime Returns: the time this item was spawned into the game world.
+//SpawnedTime Returns: the time this item was last added to the game world.
 func (i *GroundItem) SpawnedTime() time.Time {
 	return i.VarTime("spawnTime")
 }
 
 //NewPersistentGroundItem Returns a new ground item that respawns at a set rate after pickup.
+// respawn is measured in seconds, which are converted to game ticks.
 func NewPersistentGroundItem(id, amount, x, y, respawn int) *GroundItem {
-	item := &GroundItem{ID: id, Amount: amount,
-		AttributeList: entity.NewAttributeList(),
-		Entity: Entity{
-			Location: NewLocation(x, y),
-			Index:    int(ItemIndexer.Swap(ItemIndexer.Load() + 1)),
-		},
-	}
-	item.SetVar("visibility", 2)
+	item := NewGroundItem(id, amount, x, y)
 	item.SetVar("respawnTime", respawn)
 	item.SetVar("persistent", true)
 	return item
 }
 
-//NewGroundItem Creates a new ground item in the game world and returns a reference to it.
+//NewGroundItem Creates a new ground item and returns a reference to it.  Its lifecycle begins once it is added to the
+// game world with AddItem.
 func NewGroundItem(id, amount, x, y int) *GroundItem {
-	item := &GroundItem{ID: id, Amount: amount,
+	return &GroundItem{ID: id, Amount: amount,
 		AttributeList: entity.NewAttributeList(),
 		Entity: Entity{
 			Location: NewLocation(x, y),
-			Index:    int(ItemIndexer.Swap(ItemIndexer.Load() + 1)),
+			Index:    -1,
 		},
 	}
-	item.SetVar("visibility", 1)
-	tasks.TickList.Add(func() bool {
-		item.Inc("ticker", 1)
-		curTick := item.VarInt("ticker", 0)
-		// Visiblity is scoped to item owner but I guess it doesn't have an owner.
-		// Oh well, we'll just let everyone see it early.
-		if item.Visibility() == 1 && len(item.Owner) == 0 {
-			item.SetVar("visibility", 2)
-		}
-		// This keeps track of how many times we've ticked for ~71 sec since we started.
-		stage := curTick / 110
-		// ~71 sec.
-		if curTick%110 == 0 {
-			// item only seen by owner and administrators
-
-			if item.Visibility() == 1 {
-				if stage == 1 {
-					// 25% chance to stay visibility=1 until 2nd pass at ~142s...
-					if Chance(25) {
-						return false
-					}
-
-					item.SetVar("visibility", 2)
-					return false
-				}
-			}
-			// Time for everyone to see it!
-		}
-		if stage >= 3 {
-			item.Remove()
-		}
-		return item.Visibility() == 0 || stage >= 3
-	})
-
-	item.SetVar("spawnedTime", time.Now())
-	return item
 }
 
 //NewGroundItemFor Creates a new ground item with an Owner in the game world and returns a reference to it.
@@ -223,18 +182,20 @@ func NewGroundItemFor(owner uint64, id, amount, x, y int) *GroundItem {
 
 //Name returns the receivers name
 func (i *GroundItem) Name() string {
-	if i.ID >= len(definitions.Items) || i.ID < 0 {
+	def := definitions.Item(i.ID)
+	if !def.Defined() {
 		return "nil"
 	}
-	return definitions.Items[i.ID].Name
+	return def.Name
 }
 
 //Price returns the receivers base price
 func (i *GroundItem) Price() Price {
-	if i.ID >= len(definitions.Items) || i.ID < 0 {
+	def := definitions.Item(i.ID)
+	if !def.Defined() {
 		return -1
 	}
-	return Price(definitions.Items[i.ID].BasePrice)
+	return Price(def.BasePrice)
 }
 
 //DeltaAmount returns the difference between the amount of o and the amount of the receiver
@@ -249,15 +210,16 @@ func (i *GroundItem) ScalePrice(percent int) int {
 
 //Command Returns the command for this item, or nil if none.
 func (i *GroundItem) Command() string {
-	if i.ID >= len(definitions.Items) || i.ID < 0 {
+	def := definitions.Item(i.ID)
+	if !def.Defined() {
 		return "nil"
 	}
-	return definitions.Items[i.ID].Command
+	return def.Command
 }
 
 //WieldPos Returns the equip slot for this item, or -1 if none.
 func (i *GroundItem) WieldPos() int {
-	if i.ID >= len(definitions.Items) || i.ID < 0 {
+	if !definitions.Item(i.ID).Defined() {
 		return -1
 	}
 	def := definitions.Equip(i.ID)
@@ -269,23 +231,46 @@ func (i *GroundItem) WieldPos() int {
 
 //Stackable returns true if the items stackable, otherwise returns false.
 func (i *GroundItem) Stackable() bool {
-	if i.ID >= len(definitions.Items) || i.ID < 0 {
+	def := definitions.Item(i.ID)
+	if !def.Defined() {
 		return false
 	}
-	return definitions.Items[i.ID].Stackable
+	return def.Stackable
 }
 
-//Remove removes the ground item from the world.
+//Remove removes the ground item from the world.  Persistent items are added back once their respawn time has passed,
+// and any other item gives up its index for reuse.
 func (i *GroundItem) Remove() {
+	if i.Visibility() == 0 {
+		// already gone
+		return
+	}
+	i.removeFor(respawnTicks(i.VarInt("respawnTime", 10)))
+}
+
+//removeFor Takes the ground item out of the world.  Persistent items come back after ticks game ticks.
+func (i *GroundItem) removeFor(ticks int) {
 	i.UnsetVar("visibility")
 	RemoveItem(i)
-	if i.VarBool("persistent", false) {
-		go func() {
-			time.Sleep(time.Second * time.Duration(i.VarInt("respawnTime", 10)))
-			i.SetVar("visibility", 2)
-			AddItem(i)
-		}()
-	}
+	if !i.VarBool("persistent", false) {
+		i.release()
+		return
+	}
+	respawningItems.Lock()
+	respawningItems.set[i] = int(tasks.Ticks.Load()) + ticks
+	respawningItems.Unlock()
+	tasks.Schedule(ticks, func() bool {
+		respawningItems.Lock()
+		delete(respawningItems.set, i)
+		respawningItems.Unlock()
+		if !i.VarBool("persistent", false) {
+			// deleted from the world by a world edit
+			i.release()
+			return true
+		}
+		AddItem(i)
+		return true
+	})
 }
 
 //VisibleTo Returns true if the ground item is visible to this player, otherwise returns false.
@@ -348,7 +333,11 @@ func (i *Inventory) Clone() *Inventory {
 // If the item is stackable, it gets dropped no matter what, even if it is the only item and keep is 3.
 // If the item isn't stackable, the inventory is first sorted by descending BasePrice, and the first `keep` items are
 // sliced off of the top of this sorted list which leaves us with the 30-keep least valuable items.
+// An owner using Protect Item keeps one more item than keep.
 func (i *Inventory) DeathDrops(keep int) []*GroundItem {
+	if i.Owner != nil && i.Owner.PrayerActivated(PrayerProtectItem) {
+		keep++
+	}
 	// clone so we don't modify the players inventory during the sorting process
 	var pile []*GroundItem
 	if keep <= 0 {
@@ -421,7 +410,7 @@ func (i *Inventory) Size() int {
 //CanHold returns true if this inventory can hold the specified amount of the item with the specified ID
 func (i *Inventory) CanHold(id, amount int) bool {
 	var slotsReq int
-	if definitions.Items[id].Stackable || i.stackEverything {
+	if definitions.Item(id).Stackable || i.stackEverything {
 		if i.GetByID(id) == nil {
 			slotsReq += 1 + (amount / math.MaxInt32)
 		} else {
@@ -443,10 +432,10 @@ func (i *Inventory) Add(id int, qty int) int {
 	}
 	if !i.CanHold(id, qty) {
 		AddItem(NewGroundItemFor(i.Owner.UsernameHash(), id, qty, i.Owner.X(), i.Owner.Y()))
-		i.Owner.Message("Your inventory is full, the " + definitions.Items[id].Name + " drops to the ground!")
+		i.Owner.Message("Your inventory is full, the " + definitions.Item(id).Name + " drops to the ground!")
 		return -1
 	}
-	if item := i.GetByID(id); (i.stackEverything || definitions.Items[id].Stackable) && item != nil {
+	if item := i.GetByID(id); (i.stackEverything || definitions.Item(id).Stackable) && item != nil {
 		if item.Amount < 0 {
 			log.Suspicious.Println(errors.NewArgsError("*Inventory.Add(id,amt) Resulting item amount less than zero: " + strconv.FormatUint(uint64(item.Amount+qty), 10)))
 		}
@@ -500,7 +489,7 @@ func (i *Inventory) RemoveByID(id, amt int) int {
 		return -1
 	}
 	index := i.GetIndex(id)
-	if i.stackEverything || definitions.Items[id].Stackable {
+	if i.stackEverything || definitions.Item(id).Stackable {
 		if i.Get(index).Amount == amt {
 			i.Remove(index)
 		} else {
@@ -29,8 +29,20 @@ type CollisionMask int16
 //Sector Represents a sector of 48x48(2304) tiles in the game's landscape.
 type Sector struct {
 	Tiles [2304]CollisionMask
+	// Data holds the raw landscape data that the collision masks in Tiles were built from.
+	Data [2304]TileData
 }
 
+//TileData The raw landscape data of a single tile, as it is stored in the sectors of landscape.jag.
+type TileData struct {
+	Elevation, Texture, Overlay, Roof byte
+	HorizontalWall, VerticalWall      byte
+	DiagonalWall                      uint32
+}
+
+//SectorLength The length of a sector's data in landscape.jag; 48*48=2304 tiles, with 10 bytes of data per tile.
+const SectorLength = 23040
+
 //Sectors A map to store landscape sectors by their hashed file name.
 var Sectors = make(map[int]*Sector)
 var SectorsLock sync.RWMutex
@@ -39,8 +51,16 @@ var SectorsLock sync.RWMutex
 // memory for quick access.
 func LoadCollisionData() {
 	archive := jag.New(config.DataDir() + string(os.PathSeparator) + "landscape.jag")
+	if archive == nil {
+		return
+	}
 	for _, f := range archive.Files {
-		Sectors[f.Hash] = loadSector(f.Data)
+		data, err := f.Decompress()
+		if err != nil {
+			log.Warn("Problem occurred decompressing landscape sector:", err)
+			continue
+		}
+		Sectors[f.Hash] = loadSector(data)
 	}
 }
 
@@ -53,6 +73,7 @@ const (
 	ClipSouth
 	//ClipWest Bitmask to represent a wall to the east.
 	ClipWest
+	//ClipCanProjectile Bitmask to represent that everything blocking a tile lets projectiles through, e.g fences or water.
 	ClipCanProjectile
 	//ClipDiag1 Bitmask to represent a diagonal wall.
 	ClipSwNe
@@ -60,7 +81,6 @@ const (
 	ClipSeNw
 	//ClipFullBlock Bitmask to represent an object blocking an entire tile.
 	ClipFullBlock
-	// TODO: handle projectiles properly, after I define what properly means in this context
 )
 
 func ClipBit(direction int) int {
@@ -131,6 +151,15 @@ func IsTileBlocking(x, y int, bit byte, current bool) bool {
 	return CollisionData(x, y).blocked(bit, current)
 }
 
+//IsTileProjectileBlocking Returns true if the tile at x,y stops projectiles the same way IsTileBlocking stops mobs.
+// Tiles that are only blocked by things that projectiles fly over never do.
+func IsTileProjectileBlocking(x, y int, bit byte, current bool) bool {
+	if t := CollisionData(x, y); t&ClipCanProjectile == 0 {
+		return t.blocked(bit, current)
+	}
+	return false
+}
+
 func (t CollisionMask) blocked(bit byte, current bool) bool {
 	// Diagonal walls (/, \) and impassable scenary objects (|=|) both effectively disable the occupied location
 	// TODO: Is overlay clipping finished?
@@ -143,6 +172,10 @@ func sectorName(x, y int) string {
 	return fmt.Sprintf("h%dx%dy%d", (y+100)/944, regionX, regionY)
 }
 
+//emptySector A blank sector filled with zero-value tiles, shared by every location outside of the loaded sectors.
+// It must never be written to.
+var emptySector = &Sector{}
+
 func sectorFromCoords(x, y int) *Sector {
 	SectorsLock.RLock()
 	defer SectorsLock.RUnlock()
@@ -150,7 +183,7 @@ func sectorFromCoords(x, y int) *Sector {
 		return s
 	}
 	// Default to returning a blank sector filled with zero-value tiles.
-	return &Sector{}
+	return emptySector
 }
 
 func (s *Sector) tile(x, y int) CollisionMask {
@@ -166,10 +199,17 @@ func CollisionData(x, y int) CollisionMask {
 	return sectorFromCoords(x, y).tile(x, y)
 }
 
+//LandscapeData Returns the raw landscape data of the tile at x,y.
+func LandscapeData(x, y int) TileData {
+	areaX := (2304 + x) % RegionSize
+	areaY := (1776 + y - (944 * ((y + 100) / 944))) % RegionSize
+	return sectorFromCoords(x, y).Data[areaX*RegionSize+areaY]
+}
+
 //loadSector Parses raw data into data structures that make up a 48x48 map sector.
 func loadSector(data []byte) (s *Sector) {
 	// 48*48=2304 tiles per sector; 10 bytes per tile makes each sector 23040 bytes long
-	if len(data) < 23040 {
+	if len(data) < SectorLength {
 		log.Warning.Printf("Too short sector data: %d\n", len(data))
 		return nil
 	}
@@ -179,88 +219,110 @@ func loadSector(data []byte) (s *Sector) {
 	blankCount := 0
 	for x := 0; x < RegionSize; x++ {
 		for y := 0; y < RegionSize; y++ {
-			// wtf byte is at 0 on each tile?  Color or some shit, elevation, what??
-			// log.Debug(data[offset])
-			offset += 1
-			groundTexture := data[offset] & 0xFF
-			offset += 1
-			groundOverlay := data[offset] & 0xFF
-			offset += 1
-			//roofTexture := data[offset+3] & 0xFF
-			offset += 1
-			horizontalWalls := data[offset] & 0xFF
-			offset += 1
-			verticalWalls := data[offset] & 0xFF
-			offset += 1
-			diagonalWalls := binary.BigEndian.Uint32(data[offset:])
-			offset += 4
-			if groundOverlay == 250 {
-				// -6 overflows to 250, and is water tile
-				groundOverlay = definitions.OverlayWater
+			tile := TileData{
+				Elevation:      data[offset],
+				Texture:        data[offset+1],
+				Overlay:        data[offset+2],
+				Roof:           data[offset+3],
+				HorizontalWall: data[offset+4],
+				VerticalWall:   data[offset+5],
+				DiagonalWall:   binary.BigEndian.Uint32(data[offset+6:]),
 			}
-			if (groundOverlay == 0 && (groundTexture) == 0) || groundOverlay == definitions.OverlayWater || groundOverlay == definitions.OverlayBlack {
+			offset += 10
+			s.Data[x*RegionSize+y] = tile
+			if overlay := tile.overlay(); (overlay == 0 && tile.Texture == 0) || overlay == definitions.OverlayWater || overlay == definitions.OverlayBlack {
 				blankCount++
 			}
-			tileIdx := x*RegionSize + y
-			if groundOverlay > 0 && int(groundOverlay) < len(definitions.TileOverlays) && definitions.TileOverlays[groundOverlay-1].Blocked != 0 {
-				s.Tiles[tileIdx] |= ClipFullBlock
-			}
-			// if boundary := int(verticalWalls) - 1; boundary < len(definitions.BoundaryObjects) && boundary >= 0 {
-				// // log.Debugf("Out of bounds indexing attempted into definitions.BoundaryObjects[%d]; while upper bound is currently %d\n", boundary, len(definitions.BoundaryObjects)-1)
-				// if wall := definitions.BoundaryObjects[boundary]; !wall.Dynamic && wall.Solid {
-					// s.Tiles[x*RegionSize+y] |= ClipNorth
-					// if y > 0 {
-						// s.Tiles[x*RegionSize+y-1] |= ClipSouth
-					// }
-				// }
-			// }
-			if boundary := definitions.Boundary(int(verticalWalls-1)); boundary.Defined() {
-				if boundary.Solid() {
-					s.Tiles[x*RegionSize+y] |= ClipNorth
-					if x > 0 {
-						s.Tiles[x*RegionSize+y-1] |= ClipSouth
-					}
-				}
-			}
-			if boundary := definitions.Boundary(int(horizontalWalls-1)); boundary.Defined() {
-				if boundary.Solid() {
-					s.Tiles[x*RegionSize+y] |= ClipEast
-					if x > 0 {
-						s.Tiles[(x-1)*RegionSize+y] |= ClipWest
-					}
-				}
-			}
-			// if boundary := int(horizontalWalls) - 1; boundary < len(definitions.BoundaryObjects) && boundary >= 0 {
-				// // log.Debugf("Out of bounds indexing attempted into definitions.BoundaryObjects[%d]; while upper bound is currently %d\n", boundary, len(definitions.BoundaryObjects)-1)
-				// if wall := definitions.BoundaryObjects[boundary]; !wall.Dynamic && wall.Solid {
-					// s.Tiles[x*RegionSize+y] |= ClipEast
-					// if x > 0 {
-						// s.Tiles[(x-1)*RegionSize+y] |= ClipWest
-					// }
-				// }
-			// }
-			// TODO: Affect adjacent tiles in an intelligent way to determine which are solid and which are not
-			if diagonalWalls < 24000 && diagonalWalls > 0 {
-				idx := int(diagonalWalls)
-				if idx > 12000 {
-					idx -= 12000
-				}
-				idx -= 1
-				if wall := definitions.Boundary(idx); wall.Defined() && wall.Solid() {
-					if diagonalWalls > 12000 {
-						// diagonal that blocks: SW<->NE (\ aka ‾| or |_)
-						s.Tiles[x*RegionSize+y] |= ClipSwNe
-					} else {
-						// diagonal that blocks: SE<->NW (/ aka |‾ or _|)
-						s.Tiles[x*RegionSize+y] |= ClipSeNw
-					}
-				}
-			}
 		}
 	}
 	if blankCount >= 2304 {
 		return nil
 	}
+	for x := 0; x < RegionSize; x++ {
+		for y := 0; y < RegionSize; y++ {
+			s.clipTerrain(x, y, true)
+		}
+	}
 
 	return
 }
+
+//overlay Returns the tile's overlay ID.
+func (t TileData) overlay() int {
+	if t.Overlay == 250 {
+		// -6 overflows to 250, and is water tile
+		return definitions.OverlayWater
+	}
+	return int(t.Overlay)
+}
+
+//clipTerrain Applies the collision masks that the landscape data of the tile at x,y within the sector causes, or
+// when apply is false, removes them.  Walls also clip the neighboring tile on their other side, if it is in this sector.
+func (s *Sector) clipTerrain(x, y int, apply bool) {
+	tile := s.Data[x*RegionSize+y]
+	if overlay, tiles := tile.overlay(), definitions.Current().Tiles; overlay > 0 && overlay < len(tiles) && tiles[overlay-1].Blocked != 0 {
+		s.clip(x*RegionSize+y, ClipFullBlock, definitions.PermeableOverlay(overlay), apply)
+	}
+	if boundary := definitions.Boundary(int(tile.VerticalWall) - 1); boundary.Defined() && boundary.Solid() {
+		s.clip(x*RegionSize+y, ClipNorth, boundary.Permeable(), apply)
+		if y > 0 {
+			s.clip(x*RegionSize+y-1, ClipSouth, boundary.Permeable(), apply)
+		}
+	}
+	if boundary := definitions.Boundary(int(tile.HorizontalWall) - 1); boundary.Defined() && boundary.Solid() {
+		s.clip(x*RegionSize+y, ClipEast, boundary.Permeable(), apply)
+		if x > 0 {
+			s.clip((x-1)*RegionSize+y, ClipWest, boundary.Permeable(), apply)
+		}
+	}
+	// TODO: Affect adjacent tiles in an intelligent way to determine which are solid and which are not
+	if diagonalWalls := tile.DiagonalWall; diagonalWalls < 24000 && diagonalWalls > 0 {
+		idx := int(diagonalWalls)
+		if idx > 12000 {
+			idx -= 12000
+		}
+		idx -= 1
+		if wall := definitions.Boundary(idx); wall.Defined() && wall.Solid() {
+			if diagonalWalls > 12000 {
+				// diagonal that blocks: SW<->NE (\ aka ‾| or |_)
+				s.clip(x*RegionSize+y, ClipSwNe, wall.Permeable(), apply)
+			} else {
+				// diagonal that blocks: SE<->NW (/ aka |‾ or _|)
+				s.clip(x*RegionSize+y, ClipSeNw, wall.Permeable(), apply)
+			}
+		}
+	}
+}
+
+//clip Sets mask in the collision data of the tile at idx within the sector, or clears it when apply is false.
+// permeable should be true when whatever mask stands for lets projectiles through.  A tile keeps ClipCanProjectile
+// only while everything that has blocked it since it was last clear lets projectiles through.  Which masks are left
+// after clearing one can not be told apart, so a tile that loses a wall but keeps a fence blocks projectiles until it
+// is clear again; erring that way never lets a projectile through a wall.
+func (s *Sector) clip(idx int, mask CollisionMask, permeable, apply bool) {
+	if !apply {
+		s.Tiles[idx] &^= mask
+		if s.Tiles[idx] == ClipCanProjectile {
+			s.Tiles[idx] = 0
+		}
+		return
+	}
+	if !permeable {
+		s.Tiles[idx] &^= ClipCanProjectile
+	} else if s.Tiles[idx] == 0 {
+		s.Tiles[idx] |= ClipCanProjectile
+	}
+	s.Tiles[idx] |= mask
+}
+
+//clipTile Sets mask in the collision data of the tile at x,y, or clears it when apply is false.
+func clipTile(x, y int, mask CollisionMask, permeable, apply bool) {
+	areaX := (2304 + x) % RegionSize
+	areaY := (1776 + y - (944 * ((y + 100) / 944))) % RegionSize
+	s := sectorFromCoords(x, y)
+	if s == emptySector {
+		// Nothing to clip outside of the loaded sectors
+		return
+	}
+	s.clip(areaX*RegionSize+areaY, mask, permeable, apply)
+}
@@ -196,13 +196,18 @@ func (l Location) Collides(dst entity.Location) bool {
 }
 
 func (l Location) ReachableCoords(x, y int) bool {
+	return l.reachable(x, y, IsTileBlocking)
+}
+
+//reachable Returns true if nothing that blocking reports stands between this location and the next tile toward x,y.
+func (l Location) reachable(x, y int, blocking func(x, y int, bit byte, current bool) bool) bool {
 	dst := entity.Location(NewLocation(x, y))
 	if l.LongestDelta(dst) > 1 {
 		dst = l.NextTileToward(dst)
 	}
 	// first we'll take care of the simple cases, straights e.g N,E,S,W
-	if IsTileBlocking(l.X(), l.Y(), byte(ClipBit(l.DirectionToward(dst))), true) ||
-			IsTileBlocking(dst.X(), dst.Y(), byte(ClipBit(dst.DirectionToward(l))), false) {
+	if blocking(l.X(), l.Y(), byte(ClipBit(l.DirectionToward(dst))), true) ||
+			blocking(dst.X(), dst.Y(), byte(ClipBit(dst.DirectionToward(l))), false) {
 		return false
 	}
 
@@ -231,7 +236,44 @@ func (l Location) ReachableCoords(x, y int) bool {
 		// This works because we can still traverse toward
 		// our goal either vertically or horizontally,
 		// unless these checks both fail
-		return !IsTileBlocking(l.X(), dst.Y(), vmask, false) || !IsTileBlocking(dst.X(), l.Y(), hmask, false)
+		return !blocking(l.X(), dst.Y(), vmask, false) || !blocking(dst.X(), l.Y(), hmask, false)
+	}
+	return true
+}
+
+//InSight Returns true if a projectile fired from this location could fly in a straight line to dst, without being
+// stopped by any walls, diagonal walls or solid objects along the way.  Anything that projectiles can fly over, e.g
+// fences, railings or water, is seen through.
+func (l Location) InSight(dst entity.Location) bool {
+	return LineOfSight(l.X(), l.Y(), dst.X(), dst.Y())
+}
+
+//LineOfSight Returns true if a projectile could fly in a straight line from x1,y1 to x2,y2.  The line is traced one tile
+// at a time with Bresenham's algorithm, and each step is checked against the collision data the same way as walking.
+func LineOfSight(x1, y1, x2, y2 int) bool {
+	deltaX, deltaY := x2-x1, y2-y1
+	stepX, stepY := 1, 1
+	if deltaX < 0 {
+		deltaX, stepX = -deltaX, -1
+	}
+	if deltaY < 0 {
+		deltaY, stepY = -deltaY, -1
+	}
+	x, y, err := x1, y1, deltaX-deltaY
+	for x != x2 || y != y2 {
+		nextX, nextY, doubled := x, y, err*2
+		if doubled > -deltaY {
+			err -= deltaY
+			nextX += stepX
+		}
+		if doubled < deltaX {
+			err += deltaX
+			nextY += stepY
+		}
+		if !NewLocation(x, y).reachable(nextX, nextY, IsTileProjectileBlocking) {
+			return false
+		}
+		x, y = nextX, nextY
 	}
 	return true
 }
@@ -0,0 +1,206 @@
+/*
+ * Copyright (c) 2020 Zachariah Knight <aeros.storkpk@gmail.com>
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted, provided that the above copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ *
+ */
+
+package world
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/spkaeros/rscgo/pkg/definitions"
+	"github.com/spkaeros/rscgo/pkg/game/entity"
+	"github.com/spkaeros/rscgo/pkg/log"
+)
+
+//ProjectileMagic The projectile sprite the client draws for combat spells.
+const ProjectileMagic = 1
+
+//spellReach How many tiles away a spell can be cast onto its target from.
+const spellReach = 5
+
+//Spell A spell that a player is casting onto a target.  Target is the player itself for spells cast on yourself,
+// an NPC or player for spells cast on mobs, and an *Item or *GroundItem for spells cast on items.
+type Spell struct {
+	definitions.SpellDefinition
+	Caster *Player
+	Target interface{}
+}
+
+//Mob Returns the mob that this spell is being cast onto, or nil if it is not being cast onto one.
+func (s *Spell) Mob() entity.MobileEntity {
+	if m, ok := s.Target.(entity.MobileEntity); ok {
+		return m
+	}
+	return nil
+}
+
+//HasRunes Returns true if the caster is holding every rune this spell uses up, or wielding a staff in place of it.
+func (s *Spell) HasRunes() bool {
+	for id, amount := range s.Runes {
+		if !s.Caster.StaffProvides(id) && s.Caster.Inventory.CountID(id) < amount {
+			return false
+		}
+	}
+	return true
+}
+
+//StaffProvides Returns true if this player is wielding a staff that stands in for the rune with the provided ID.
+func (p *Player) StaffProvides(runeID int) bool {
+	for staff, id := range definitions.StaffRunes() {
+		if id == runeID && p.Inventory.Equipped(staff) {
+			return true
+		}
+	}
+	return false
+}
+
+//Cast Checks that the caster is able to cast this spell right now, uses up its runes, and rolls for whether it fails,
+// telling the caster how it went.  Casting successfully gives the caster the spells magic experience.
+// Returns true if the spell was cast successfully, and its effects should happen.
+func (s *Spell) Cast() bool {
+	p := s.Caster
+	p.ResetPath()
+	if lastCast := p.VarTime("lastSpell"); !lastCast.IsZero() && lastCast.After(time.Now()) {
+		p.Message("@que@You need to wait " + strconv.Itoa(int(time.Until(lastCast).Seconds())) + " seconds before you can cast another spell")
+		return false
+	}
+
+	lvDelta := p.Skills().Current(entity.StatMagic) - s.Level
+	if lvDelta < 0 {
+		p.Message("Your magic ability is not high enough for this spell.")
+		return false
+	}
+
+	if !s.HasRunes() {
+		log.Cheatf("%v casted spell with not enough runes (shouldn't happen)\n", p)
+		p.Message("You don't have all the reagents you need for this spell")
+		return false
+	}
+	for id, amount := range s.Runes {
+		if !p.StaffProvides(id) {
+			p.Inventory.RemoveByID(id, amount)
+		}
+	}
+
+	// ORSC does the below check in steps, but if clamped with helper funcs we can do it in one check.
+	if float64(lvDelta) < 10-math.Min(math.Max(float64(p.MagicPoints()-5)/5, 0), 5) && p.RandomIncl(0, (lvDelta+2)*2) == 0 {
+		p.SetVar("lastSpell", time.Now().Add(time.Second*20))
+		p.PlaySound("spellfail")
+		p.Message("The spell fails! You may try again in 20 seconds")
+		return false
+	}
+	p.SetVar("lastSpell", time.Now().Add(time.Millisecond*1280))
+	p.PlaySound("spellok")
+	p.Message("Cast spell successfully")
+	p.IncExp(entity.StatMagic, s.Experience)
+	return true
+}
+
+//Fire Hits the target mob of this spell with a missile of the provided strength, showing the projectile to everyone
+// nearby.
+func (s *Spell) Fire(power int) {
+	p, target := s.Caster, s.Mob()
+	if target == nil {
+		return
+	}
+	hit := int(math.Min(float64(target.Skills().Current(entity.StatHits)), float64(p.MagicDamage(target, float64(power)))))
+	p.Enqueue(playerEvents, NewProjectile(p, target, ProjectileMagic))
+	if target.DamageFrom(p, hit, DamageMagic) {
+		return
+	}
+	if targetp := AsPlayer(target); targetp != nil {
+		targetp.Message("Warning! " + p.Username() + " is shooting at you!")
+	}
+}
+
+//validTarget Returns true if target is something that a spell of the provided type can be cast onto by this player.
+func (p *Player) validTarget(kind int, target interface{}) bool {
+	switch kind {
+	case definitions.SpellTeleport, definitions.SpellSelf:
+		return target == p
+	case definitions.SpellMob:
+		switch t := target.(type) {
+		case *NPC:
+			return t != nil
+		case *Player:
+			return t != nil && t != p
+		}
+	case definitions.SpellItem:
+		switch t := target.(type) {
+		case *Item:
+			return t != nil
+		case *GroundItem:
+			return t != nil
+		}
+	case definitions.SpellObject:
+		t, ok := target.(*Object)
+		return ok && t != nil
+	}
+	return false
+}
+
+//CastSpell Casts the spell with the provided ID onto target.  This takes care of everything that every spell of a kind
+// has in common: walking to within reach of mobs and ground items, checking whether mobs can be attacked, and keeping
+// teleports out of zones that block them.
+//
+// What the spell does is left to the script bound to it with bind.spell, which is passed a *Spell to check anything
+// it needs to and then call Cast on.  Missile spells without a script just fire their missile.
+func (p *Player) CastSpell(id int, target interface{}) {
+	def := definitions.Spell(id)
+	if !def.Defined() {
+		log.Debug("Couldn't find definition for spell:", id)
+		return
+	}
+	if !p.validTarget(def.Type, target) {
+		log.Cheatf("%v attempted to cast spell %d (%v) onto an invalid target: %v\n", p, id, def.Name, target)
+		return
+	}
+	spell := &Spell{def, p, target}
+	run := func() {
+		if effect, ok := SpellTriggers[id]; ok && effect != nil {
+			effect(p, spell)
+			return
+		}
+		if def.Type == definitions.SpellMob && def.Damage > 0 {
+			if spell.Cast() {
+				spell.Fire(def.Damage)
+			}
+			return
+		}
+		p.Message("@que@@or2@Not yet added")
+	}
+
+	switch def.Type {
+	case definitions.SpellTeleport:
+		if !p.CanTeleport() {
+			return
+		}
+	case definitions.SpellMob:
+		mob := spell.Mob()
+		p.WalkingSightAction(mob, spellReach, func() {
+			if !p.CanAttack(mob) {
+				if p.State()&StateFightingDuel == StateFightingDuel && p.Duel.Target == mob && !p.DuelMagic() {
+					p.Message("Magic cannot be used during this duel!")
+				}
+				p.ResetPath()
+				return
+			}
+			p.ResetAllExceptDueling()
+			run()
+		})
+		return
+	case definitions.SpellItem:
+		if item, ok := target.(*GroundItem); ok {
+			p.WalkingSightAction(item, spellReach, run)
+			return
+		}
+	}
+	run()
+}
@@ -0,0 +1,192 @@
+/*
+ * Copyright (c) 2020 Zachariah Knight <aeros.storkpk@gmail.com>
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted, provided that the above copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ *
+ */
+
+package world
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"sync"
+
+	"github.com/spkaeros/rscgo/pkg/config"
+	"github.com/spkaeros/rscgo/pkg/game/entity"
+	"github.com/spkaeros/rscgo/pkg/jag"
+	"github.com/spkaeros/rscgo/pkg/log"
+	"github.com/spkaeros/rscgo/pkg/strutil"
+)
+
+//ErrInvalidTile Returned when an edit would store a value that does not fit into the landscape data of a tile.
+var ErrInvalidTile = errors.New("value out of range for landscape tile")
+
+//landscapeEdits Serializes edits to the landscape, and keeps the hashes of every sector that has been edited since the
+// landscape was loaded, so that only those sectors need to be replaced when it is exported.
+var landscapeEdits = struct {
+	sync.Mutex
+	sectors map[int]bool
+}{sectors: make(map[int]bool)}
+
+//landscapeFile Returns the path to the landscape archive that the server loads its sectors from.
+func landscapeFile() string {
+	return config.DataDir() + string(os.PathSeparator) + "landscape.jag"
+}
+
+//Encode Returns the sector's landscape data, in the same 23040 byte format that landscape.jag stores it in.
+func (s *Sector) Encode() []byte {
+	data := make([]byte, SectorLength)
+	for i, tile := range s.Data {
+		offset := i * 10
+		data[offset] = tile.Elevation
+		data[offset+1] = tile.Texture
+		data[offset+2] = tile.Overlay
+		data[offset+3] = tile.Roof
+		data[offset+4] = tile.HorizontalWall
+		data[offset+5] = tile.VerticalWall
+		binary.BigEndian.PutUint32(data[offset+6:], tile.DiagonalWall)
+	}
+	return data
+}
+
+//EditTile Calls edit with the landscape data of the tile at x,y, and then recomputes the collision masks of that tile
+// and its neighbors in the running world.  Objects near the tile have their collision masks applied again afterwards,
+// so that clearing a wall or blocked overlay does not also clear the masks of an object that shares the same edge.
+func EditTile(x, y int, edit func(*TileData)) {
+	landscapeEdits.Lock()
+	defer landscapeEdits.Unlock()
+
+	hash := strutil.JagHash(sectorName(x, y))
+	SectorsLock.Lock()
+	s := Sectors[hash]
+	if s == nil {
+		// blank sectors are not kept after loading, so the first edit to one needs to allocate it
+		s = &Sector{}
+		Sectors[hash] = s
+	}
+	SectorsLock.Unlock()
+	landscapeEdits.sectors[hash] = true
+
+	areaX := (2304 + x) % RegionSize
+	areaY := (1776 + y - (944 * ((y + 100) / 944))) % RegionSize
+	s.clipTerrain(areaX, areaY, false)
+	edit(&s.Data[areaX*RegionSize+areaY])
+	s.clipTerrain(areaX, areaY, true)
+
+	// scenary can span a few tiles from where it is placed, so anything close enough might overlap the edited tile
+	for _, r := range VisibleRegions(x-RegionSize/2, y-RegionSize/2) {
+		r.Objects.Range(func(e entity.Entity) {
+			if o, ok := e.(*Object); ok && o.X() >= x-4 && o.X() <= x+1 && o.Y() >= y-4 && o.Y() <= y+1 {
+				clipObject(o, true)
+			}
+		})
+	}
+}
+
+//SetTileOverlay Changes the overlay of the tile at x,y; 0 removes it.
+func SetTileOverlay(x, y, overlay int) error {
+	if overlay < 0 || overlay > 0xFF {
+		return ErrInvalidTile
+	}
+	EditTile(x, y, func(tile *TileData) {
+		tile.Overlay = byte(overlay)
+	})
+	return nil
+}
+
+//SetTileElevation Changes the ground height of the tile at x,y.
+func SetTileElevation(x, y, elevation int) error {
+	if elevation < 0 || elevation > 0xFF {
+		return ErrInvalidTile
+	}
+	EditTile(x, y, func(tile *TileData) {
+		tile.Elevation = byte(elevation)
+	})
+	return nil
+}
+
+//SetHorizontalWall Changes the boundary that makes up the wall on the east edge of the tile at x,y; -1 removes it.
+func SetHorizontalWall(x, y, boundary int) error {
+	if boundary < -1 || boundary >= 0xFF {
+		return ErrInvalidTile
+	}
+	EditTile(x, y, func(tile *TileData) {
+		tile.HorizontalWall = byte(boundary + 1)
+	})
+	return nil
+}
+
+//SetVerticalWall Changes the boundary that makes up the wall on the north edge of the tile at x,y; -1 removes it.
+func SetVerticalWall(x, y, boundary int) error {
+	if boundary < -1 || boundary >= 0xFF {
+		return ErrInvalidTile
+	}
+	EditTile(x, y, func(tile *TileData) {
+		tile.VerticalWall = byte(boundary + 1)
+	})
+	return nil
+}
+
+//SetDiagonalWall Changes the boundary that makes up the diagonal wall across the tile at x,y; -1 removes it.
+// The wall runs from southwest to northeast when flipped is set, and otherwise from southeast to northwest.
+func SetDiagonalWall(x, y, boundary int, flipped bool) error {
+	if boundary < -1 || boundary >= 12000 {
+		return ErrInvalidTile
+	}
+	EditTile(x, y, func(tile *TileData) {
+		tile.DiagonalWall = 0
+		if boundary >= 0 {
+			tile.DiagonalWall = uint32(boundary + 1)
+			if flipped {
+				tile.DiagonalWall += 12000
+			}
+		}
+	})
+	return nil
+}
+
+//ExportLandscape Writes a new landscape archive to file, made of the archive that the server loaded its landscape from,
+// with every sector that has been edited since then replaced by its current data.  An empty file name replaces the
+// server's own landscape archive, which makes the edits persist across restarts.
+func ExportLandscape(file string) error {
+	if len(file) <= 0 {
+		file = landscapeFile()
+	}
+	archive, err := jag.Open(landscapeFile())
+	if err != nil {
+		return err
+	}
+
+	landscapeEdits.Lock()
+	SectorsLock.RLock()
+	for hash := range landscapeEdits.sectors {
+		archive.PutHash(hash, Sectors[hash].Encode())
+	}
+	edited := len(landscapeEdits.sectors)
+	SectorsLock.RUnlock()
+	landscapeEdits.Unlock()
+
+	// written beside the destination first, so that a failed export can never leave a truncated archive behind
+	out, err := os.Create(file + ".tmp")
+	if err != nil {
+		return err
+	}
+	if err := archive.Write(out, jag.CompressArchive); err != nil {
+		out.Close()
+		os.Remove(file + ".tmp")
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(file + ".tmp")
+		return err
+	}
+	if err := os.Rename(file+".tmp", file); err != nil {
+		return err
+	}
+	log.Commandf("Exported landscape with %d edited sectors to '%v'\n", edited, file)
+	return nil
+}
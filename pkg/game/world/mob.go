@@ -383,7 +383,7 @@ func StartCombat(attacker, defender entity.MobileEntity) {
 		}()
 
 		// Paralyze Monster goes into effect right here, we just return before the npc can do anything
-		if defender.IsPlayer() && attacker.IsNpc() && defender.PrayerActivated(12) {
+		if defender.IsPlayer() && attacker.IsNpc() && defender.PrayerActivated(PrayerParalyzeMonster) {
 			return false
 		}
 
@@ -391,6 +391,9 @@ func StartCombat(attacker, defender entity.MobileEntity) {
 		if defender.DamageFrom(attacker, nextHit, 0) {
 			return true
 		}
+		if nextHit > 0 {
+			meleeHitEffects(attacker, defender)
+		}
 		return false
 	})
 }
@@ -611,7 +614,7 @@ func (m *Mob) IncPoints(id string, amt int) {
 		if points - int(math.Abs(float64(amt))) <= 1 {
 			m.SetVar(id+"_points", 1)
 		} else {
-			m.SetVar(id+"_points", points)
+			m.SetVar(id+"_points", points+amt)
 		}
 	}
 }
@@ -648,68 +651,29 @@ func (m *Mob) PrayerActivated(i int) bool {
 	return m.Prayers[i]
 }
 
-func (m *Mob) PrayerModifiers() (modifiers [3]int) {
-	// how much each tier of prayers affects combat, by percentage
-	power := [3]int{5, 10, 15}
-	
-	// combat prayers ordered as: attack, defense, strength
-	// same as stat panel order; this makes the array index match
-	prayers := [...] [3]int{
-		{2, 5, 11},
-		{0, 3, 9},
-		{1, 4, 10},
-	}
-	for skillIdx, modifierList := range prayers {
-		for tier, prayer := range modifierList {
-			if m.PrayerActivated(prayer) {
-				modifiers[skillIdx] = power[tier]
-			}
-		}
-	}
-	return modifiers
+//PrayerModifiers Returns the percentage that this mobs active prayers boost its attack, defense and strength by.
+func (m *Mob) PrayerModifiers() [3]int {
+	return DefaultCombatFormula.PrayerModifiers(m)
 }
 
+//StyleBonus Returns the invisible levels that this mobs fight mode adds to the stat with the provided ID.
 func (m *Mob) StyleBonus(stat int) int {
-	mode := m.FightMode()
-	if mode == 0 {
-		return 1
-	}
-	if mode == (stat+1)%3+1 {
-		return 3
-	}
-	return 0
+	return DefaultCombatFormula.StyleBonus(m, stat)
 }
 
 //MaxMeleeDamage Calculates and returns the current max hit for this mob, based on many variables.
 func (m *Mob) MaxMeleeDamage() float64 {
-	skillPower := (float64(m.Skills().Current(entity.StatStrength)) * (float64(m.PrayerModifiers()[entity.StatStrength]) / 100 + 1)) + 
-			float64(m.StyleBonus(entity.StatStrength))
-	weapPower := float64(m.PowerPoints()) * 0.00175 + 0.1
-	return math.Ceil(skillPower * weapPower)
-	// return (
-			// (float64(m.Skills().Current(entity.StatStrength)) * ((float64(m.PrayerModifiers()[entity.StatStrength]) / 100 + 1)) +
-			// float64(m.StyleBonus(entity.StatStrength))) *
-				// float64(m.PowerPoints())*0.00175+0.1) + 1.05
+	return DefaultCombatFormula.MaxMeleeDamage(m)
 }
 
 //AttackPoints Calculates and returns the accuracy capability of this mob, based on many variables, as a single variable.
 func (m *Mob) AttackPoints() float64 {
-	// skillAccuracy := float64(m.Skills().Current(entity.StatAttack)) * float64(m.PrayerModifiers()[entity.StatAttack] / 100 + 1) +
-	skillAccuracy := (float64(m.Skills().Current(entity.StatAttack)) * (float64(m.PrayerModifiers()[entity.StatAttack]) / 100 + 1)) + 
-			float64(m.StyleBonus(entity.StatAttack))
-	weapAccuracy := float64(m.AimPoints()) * 0.00175 + 0.1
-	return math.Ceil(skillAccuracy * weapAccuracy)
-	// return ((float64(m.Skills().Current(entity.StatAttack))*m.PrayerModifiers()[entity.StatAttack])+float64(m.StyleBonus(entity.StatAttack)))*((float64(m.AimPoints())*0.00175)+0.1) + 1.05
+	return DefaultCombatFormula.AttackPoints(m)
 }
 
 //DefensePoints Calculates and returns the defensive capability of this mob, based on many variables, as a single variable.
 func (m *Mob) DefensePoints() float64 {
-	// skillAccuracy := float64(m.Skills().Current(entity.StatDefense)) * float64(m.PrayerModifiers()[entity.StatDefense] / 100 + 1) +
-	skillDefense := (float64(m.Skills().Current(entity.StatDefense)) * (float64(m.PrayerModifiers()[entity.StatDefense]) / 100 + 1)) + 
-			float64(m.StyleBonus(entity.StatDefense))
-	armourDefense := float64(m.ArmourPoints()) * 0.00175 + 0.1
-	return math.Ceil(skillDefense * armourDefense)
-	// return ((float64(m.Skills().Current(entity.StatDefense))*m.PrayerModifiers()[entity.StatDefense])+float64(m.StyleBonus(entity.StatDefense)))*((float64(m.ArmourPoints())*0.00175)+0.1) + 1.05
+	return DefaultCombatFormula.DefensePoints(m)
 }
 
 func (m *Mob) CombatRng() *rand.Rand {
@@ -744,27 +708,13 @@ func (m *Mob) Isaac() *rand.Rand {
 // random percentage check around a call to GenerateHit.
 func (m *Mob) MagicDamage(target entity.MobileEntity, maximum float64) int {
 	// TODO: Tweak the defense/armor hit/miss formula to better match RSC--or at least verify this is somewhat close?
-	if ChanceByte(int(math.Max(0.0, math.Min(212.0, 256.0 * float64(m.MagicPoints()) / (target.DefensePoints()*4.0))))) {
-		return m.GenerateHit(maximum)
-	}
-
-	return 0
+	return RollHit(m, float64(m.MagicPoints()), target.DefensePoints(), maximum)
 }
 
 //GenerateHit returns a normally distributed random number from this mobs PRNG instance,
-// between 1 and max, inclusive.  This is widely believed to be how Jagex generated damage hits,
-// and it feels accurate while playing.
+// between 1 and max, inclusive.
 func (m *Mob) GenerateHit(max float64) int {
-	mean := max / 2.0
-	value := 0.0
-	for tries := 0; tries < 25; tries++ {
-		value = math.Floor(mean + m.CombatRng().NormFloat64() * (max / 3.0))
-		if value >= 1.0 && value <= max {
-			return int(value)
-		} 
-	}
-	// after 25 out of bounds values, we just clamp whatever value we do have into bounds
-	return int(math.Max(1, math.Min(max, value)))
+	return DefaultCombatFormula.GenerateHit(m.CombatRng(), max)
 }
 
 func (n *NPC) MeleeDamage(target entity.MobileEntity) int {
@@ -780,11 +730,7 @@ func (n *NPC) MeleeDamage(target entity.MobileEntity) int {
 // Kenix mentioned running monte-carlo sims when coming up with it, so presumably this formula matched up
 // statistically fairly well to the real game.  I can not say for sure as I didn't do these things myself, though.
 func (m *Mob) MeleeDamage(target entity.MobileEntity) int {
-	if ChanceByte(int(math.Max(0.0, math.Min(212.0, 256.0 * m.AttackPoints() / (target.DefensePoints()*4.0))))) {
-		return m.GenerateHit(m.MaxMeleeDamage())
-	}
-
-	return 0
+	return RollHit(m, m.AttackPoints(), target.DefensePoints(), m.MaxMeleeDamage())
 }
 
 //Random This generates a pseudo-random integer using a member instance of ISAAC.
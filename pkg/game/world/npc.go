@@ -31,6 +31,8 @@ type NPC struct {
 	Boundaries                    [2]entity.Location
 	Steps, Ticks				  int
 	meleeRangeDamage, magicDamage damages
+	// the part of meleeRangeDamage that was dealt with ranged attacks
+	rangedDamage                  damages
 }
 
 type (
@@ -67,16 +69,20 @@ func NewNpc(id, startX, startY, minX, maxX, minY, maxY int) *NPC {
 		magicDamage: damages {
 			damageTable: make(map[uint64]int),
 		},
+		rangedDamage: damages {
+			damageTable: make(map[uint64]int),
+		},
 		Boundaries: [2]entity.Location{NewLocation(minX, minY), NewLocation(maxX, maxY)},
 	}
 	defer Npcs.Add(n)
 	n.StartPoint = n.Clone()
 	if n.valid() {
+		def := definitions.Npc(id)
 		skills := [18] int {
-			definitions.Npcs[id].Attack,
-			definitions.Npcs[id].Defense,
-			definitions.Npcs[id].Strength,
-			definitions.Npcs[id].Hits,
+			def.Attack,
+			def.Defense,
+			def.Strength,
+			def.Hits,
 		}
 		for i, lvl := range skills {
 			n.Skills().SetCur(i, lvl)
@@ -103,7 +109,7 @@ func (n *NPC) CacheDamage(hash uint64, dmg int) {
 }
 
 func (n *NPC) valid() bool {
-	return n.ID < len(definitions.Npcs)
+	return definitions.Npc(n.ID).Defined()
 }
 
 // Returns true if this NPCs definition has the attackable hostility bit set.
@@ -112,7 +118,7 @@ func (n *NPC) Attackable() bool {
 		return false
 	}
 
-	return definitions.Npcs[n.ID].Hostility&1 == 1
+	return definitions.Npc(n.ID).Hostility&1 == 1
 }
 
 // Returns true if this NPCs definition has the retreat near death hostility bit set.
@@ -122,7 +128,7 @@ func (n *NPC) Retreats() bool {
 		return false
 	}
 
-	return definitions.Npcs[n.ID].Hostility&2 == 2
+	return definitions.Npc(n.ID).Hostility&2 == 2
 }
 
 // Returns true if this NPCs definition has the aggressive hostility bit set.
@@ -131,21 +137,21 @@ func (n *NPC) Aggressive() bool {
 		return false
 	}
 
-	return definitions.Npcs[n.ID].Hostility&4 == 4
+	return definitions.Npc(n.ID).Hostility&4 == 4
 }
 
 func (n *NPC) Name() string {
 	if !n.valid() {
 		return "nil"
 	}
-	return definitions.Npcs[n.ID].Name
+	return definitions.Npc(n.ID).Name
 }
 
 func (n *NPC) Command() string {
 	if !n.valid() {
 		return "nil"
 	}
-	return definitions.Npcs[n.ID].Command
+	return definitions.Npc(n.ID).Command
 }
 
 type NpcBlockingTrigger struct {
@@ -159,6 +165,8 @@ var NpcDeathTriggers []NpcBlockingTrigger
 func (n *NPC) rewardKillers() (winner *Player) {
 	n.meleeRangeDamage.RLock()
 	defer n.meleeRangeDamage.RUnlock()
+	n.rangedDamage.RLock()
+	defer n.rangedDamage.RUnlock()
 	totalExp := float64(n.ExperienceReward())
 	amount := 0
 	total := 0.0
@@ -171,7 +179,13 @@ func (n *NPC) rewardKillers() (winner *Player) {
 				winner = player
 				amount = damage
 			}
-			player.DistributeMeleeExp(totalExp / float64(total) * float64(damage))
+			ranged := n.rangedDamage.damageTable[username]
+			if ranged > 0 {
+				player.DistributeRangedExp(totalExp / float64(total) * float64(ranged))
+			}
+			if damage > ranged {
+				player.DistributeMeleeExp(totalExp / float64(total) * float64(damage - ranged))
+			}
 		}
 	}
 	return winner
@@ -189,13 +203,13 @@ func (n *NPC) Killed(killer entity.MobileEntity) {
 	// first pass is to find the total so we can split up the exp properly
 	// this is because the total is not guaranteed to match max hitpoints since
 	// the NPC can heal after damage has been dealt, among other things
-	if dropPlayer != nil {
-		AddItem(NewGroundItemFor(dropPlayer.UsernameHash(), DefaultDrop, 1, n.X(), n.Y()))
-	} else {
-		AddItem(NewGroundItem(DefaultDrop, 1, n.X(), n.Y()))
+	for _, item := range RollDrops(n.ID) {
+		DropItem(dropPlayer, NewGroundItem(item.ID, item.Amount, n.X(), n.Y()))
 	}
 
-	killer.ResetFighting()
+	if killer != nil {
+		killer.ResetFighting()
+	}
 	n.ResetFighting()
 	n.Remove()
 	
@@ -212,9 +226,14 @@ func (n *NPC) Remove() {
 }
 
 func (n *NPC) Respawn() {
+	if n.VarBool("deleted", false) {
+		// deleted from the world by a world edit
+		return
+	}
 	for i := 0; i < 18; i++ {
 		n.Skills().SetCur(i, n.Skills().Maximum(i))
 	}
+	ClearEffects(n)
 	n.UnsetVar("removed")
 	n.SetLocation(n.StartPoint.Clone(), true)
 	n.meleeRangeDamage.Lock()
@@ -223,6 +242,21 @@ func (n *NPC) Respawn() {
 	n.magicDamage.Lock()
 	defer n.magicDamage.Unlock()
 	n.magicDamage.damageTable = make(damageTable)
+	n.rangedDamage.Lock()
+	defer n.rangedDamage.Unlock()
+	n.rangedDamage.damageTable = make(damageTable)
+}
+
+//Provoke Turns the NPC on the player, so that it chases it down and attacks it the same way that aggressive NPCs do,
+// until it reaches it or loses sight of it.
+func (n *NPC) Provoke(p *Player) {
+	n.SetVar("targetPlayer", p)
+	n.SetVar("provoked", true)
+}
+
+//Provoked Returns true if the NPC was turned on a player with Provoke, and is still after it.
+func (n *NPC) Provoked() bool {
+	return n.VarBool("provoked", false)
 }
 
 //TraversePath If the mob has a path, calling this method will change the mobs location to the next location described by said Path data structure.  This should be called no more than once per game tick.
@@ -231,11 +265,13 @@ func (n *NPC) TraversePath() {
 	if p := n.VarPlayer("targetPlayer"); p != nil {
 		if !n.Near(p, 6) {
 			n.UnsetVar("targetPlayer")
+			n.UnsetVar("provoked")
 		}
-		if n.Aggressive() && n.Near(p, 1) && !n.Collides(p) && !p.Busy() && !p.IsFighting() {
+		if (n.Aggressive() || n.Provoked()) && n.Near(p, 1) && !n.Collides(p) && !p.Busy() && !p.IsFighting() && !p.PrayerActivated(PrayerParalyzeMonster) {
 			if t := p.SessionCache().VarTime("lastFight"); time.Since(t) < 1920*time.Millisecond {
 				return
 			}
+			n.UnsetVar("provoked")
 			StartCombat(n, p)
 			return
 		}
@@ -44,9 +44,9 @@ func (o *Object) Name() string {
 		return "nil"
 	}
 	if o.Boundary {
-		return definitions.BoundaryObjects[o.ID].Name
+		return definitions.Boundary(o.ID).Name
 	}
-	return definitions.ScenaryObjects[o.ID].Name
+	return definitions.Scenary(o.ID).Name
 }
 
 //Name checks if an object definition exists for this object, and if so returns the name associated with it.
@@ -63,9 +63,9 @@ func (o *Object) Command(click int) string {
 		return "nil"
 	}
 	if o.Boundary {
-		return definitions.BoundaryObjects[o.ID].Commands[click]
+		return definitions.Boundary(o.ID).Commands[click]
 	}
-	return definitions.ScenaryObjects[o.ID].Commands[click]
+	return definitions.Scenary(o.ID).Commands[click]
 }
 
 //ClipType returns a unique identifier representing what kind of collisions with other entities
@@ -85,12 +85,12 @@ func (o *Object) ClipType() int {
 		return 0
 	}
 	if o.Boundary {
-		if definitions.BoundaryObjects[o.ID].Door() {
+		if definitions.Boundary(o.ID).Door() {
 			return 2
 		}
 		return 3
 	}
-	return definitions.ScenaryObjects[o.ID].SolidityType
+	return definitions.Scenary(o.ID).SolidityType
 }
 
 func (o *Object) TypeData() mapBarrier {
@@ -101,14 +101,7 @@ func (o *Object) TypeData() mapBarrier {
 }
 
 func (o *Object) Defined() bool {
-	var set interface{Size() int} = definitions.BoundaryObjects
-	if !o.Boundary {
-		set = definitions.ScenaryObjects
-	}
-	if o.ID < 0 || o.ID >= set.Size() {
-		return false
-	}
-	return true
+	return o.TypeData().Defined()
 }
 
 //Width The width measured in game tiles that this object takes up in the game world.
@@ -121,7 +114,7 @@ func (o *Object) Width() int {
 		// no large ass door boundarys exist, we take up 1x1 tiles
 		return 1
 	}
-	return definitions.ScenaryObjects[o.ID].Width()
+	return definitions.Scenary(o.ID).Width()
 }
 
 //Height The height measured in game tiles that this object takes up in the game world.
@@ -134,7 +127,7 @@ func (o *Object) Height() int {
 		// no large ass door boundarys exist, we take up 1x1 tiles
 		return 1
 	}
-	return definitions.ScenaryObjects[o.ID].Height()
+	return definitions.Scenary(o.ID).Height()
 }
 
 func (o *Object) Boundaries() [2]entity.Location {
@@ -130,13 +130,15 @@ func ShopOpen(shop *Shop) (p *net.Packet) {
 	return p
 }
 
+//SleepWord Builds a packet containing an image of the word that the sleeping player has to type in to wake up.
 func SleepWord(player *Player) (p *net.Packet) {
-	// TODO: Figure this out
-	return net.NewEmptyPacket(117)
+	return net.NewEmptyPacket(117).AddBytes(sleepWordImage(player.VarString("sleepWord", "")))
 }
 
+//SleepFatigue Builds a packet containing the fatigue that the sleeping player will wake up with, scaled the same way as
+// in Fatigue.
 func SleepFatigue(player *Player) (p *net.Packet) {
-	return net.NewEmptyPacket(244).AddUint16(uint16(player.VarInt("sleepFatigue", 0)))
+	return net.NewEmptyPacket(244).AddUint16(uint16(player.VarInt("sleepFatigue", 0) / 100))
 }
 
 var SleepClose = net.NewEmptyPacket(84)
@@ -646,7 +648,7 @@ func InventoryItems(player *Player) (p *net.Packet) {
 		} else {
 			p.AddUint16(uint16(item.ID))
 		}
-		if definitions.Items[item.ID].Stackable {
+		if definitions.Item(item.ID).Stackable {
 			p.AddSmart1632(item.Amount)
 		}
 		return true
@@ -24,6 +24,7 @@ import (
 	"github.com/gobwas/ws"
 	"github.com/gobwas/ws/wsutil"
 
+	"github.com/spkaeros/rscgo/pkg/config"
 	"github.com/spkaeros/rscgo/pkg/definitions"
 	"github.com/spkaeros/rscgo/pkg/errors"
 	"github.com/spkaeros/rscgo/pkg/game/entity"
@@ -93,7 +94,17 @@ func (p *Player) UpdatedRegions() {
 	p.SetVar("needsObjects", true)
 }
 
+//CanAttack Returns true if this player is allowed to attack target, telling it why not otherwise.  Attacking players
+// is only allowed when both players stand in PvP zones, within the level range of their zones.  Players, and anything
+// in a PvP zone, may only be attacked by the mob that they are already fighting, unless they stand in a multi-combat
+// zone.
 func (p *Player) CanAttack(target entity.MobileEntity) bool {
+	if target.IsFighting() && target.FightTarget() != p {
+		if zone := ZoneAt(target); (target.IsPlayer() || zone.Pvp()) && !zone.Multi() {
+			p.Message("Your opponent is busy!")
+			return false
+		}
+	}
 	if target := AsNpc(target); target != nil {
 		return target.Attackable()
 	}
@@ -101,9 +112,8 @@ func (p *Player) CanAttack(target entity.MobileEntity) bool {
 		return p.Duel.Target == target && p.DuelMagic()
 	}
 	targetp := AsPlayer(target)
-	ourWild := p.Wilderness()
-	targetWild := targetp.Wilderness()
-	if ourWild < 1 || targetWild < 1 {
+	ourZone, targetZone := p.Zone(), targetp.Zone()
+	if !ourZone.Pvp() || !targetZone.Pvp() {
 		p.Message("You cannot attack other players outside of the wilderness!")
 		return false
 	}
@@ -111,17 +121,29 @@ func (p *Player) CanAttack(target entity.MobileEntity) bool {
 	if delta < 0 {
 		delta = -delta
 	}
-	if delta > ourWild {
+	if delta > ourZone.LevelRange(p) {
 		p.Message("You must move to at least level " + strconv.Itoa(delta) + " wilderness to attack " + targetp.Username() + "!")
 		return false
 	}
-	if delta > targetWild {
+	if delta > targetZone.LevelRange(targetp) {
 		p.Message(targetp.Username() + " is not in high enough wilderness for you to attack!")
 		return false
 	}
 	return true
 }
 
+//CanTeleport Returns true if this player is allowed to teleport away from where it stands, telling it why not otherwise.
+func (p *Player) CanTeleport() bool {
+	if p.Zone().NoTeleport() {
+		p.Message("A mysterious force blocks your teleport spell!")
+		if p.Wilderness() >= 20 {
+			p.Message("You can't use teleport after level 20 wilderness")
+		}
+		return false
+	}
+	return true
+}
+
 func (p *Player) Username() string {
 	return strutil.Base37.Decode(p.VarLong("username", strutil.Base37.Encode("NIL")))
 }
@@ -244,12 +266,31 @@ func (p *Player) UpdateStatus(status bool) {
 // with a straight line of sight, e.g no intersecting boundaries, large objects, walls, etc.
 // Runs everything on game engine ticks, retries until catastrophic failure or success.
 func (p *Player) WalkingRangedAction(t entity.MobileEntity, fn func()) {
-	//if t == nil || p.State()&StateFightingDuel == 0 || (p.State()&StateFightingDuel == StateFightingDuel &&
-	//		(!p.VarBool("duelCanMagic", true) || t != p.DuelTarget())) {
-	//	p.ResetPath()
-	//	return
-	//}
-	p.WalkingArrivalAction(t, 5, fn)
+	p.WalkingSightAction(t, 5, fn)
+}
+
+//WalkingSightAction Runs `action` once arriving within dist tiles of `target`, from where a projectile could
+// fly straight to it.  Until then, the player keeps stepping toward the target.
+// Runs everything on game engine ticks, retries until catastrophic failure or success.
+func (p *Player) WalkingSightAction(t entity.Location, dist int, action func()) {
+	p.SetTickAction(func() bool {
+		if p.Near(t, dist) && p.InSight(t) {
+			p.ResetPath()
+			action()
+			return false
+		}
+		if p.FinishedPath() {
+			pivotList := p.PivotTo(t)
+			if len(pivotList[0]) == 0 || len(pivotList[1]) == 0 {
+				// no reasonable direct path to target
+				p.Message("I can't reach that.")
+				return false
+			}
+			p.ResetPath()
+			p.SetCoords(pivotList[0][0], pivotList[1][0], false)
+		}
+		return true
+	})
 }
 
 //WalkingArrivalAction Runs `action` once arriving within dist (min 1 max 2 tiles)
@@ -397,6 +438,12 @@ func (p *Player) TraversePath() {
 		return
 	}
 
+	if !config.MembersWorld() && ZoneAt(dst).Members() && !p.Zone().Members() {
+		p.Message("You need to be on a members world to go there")
+		p.ResetPath()
+		return
+	}
+
 	p.SetLocation(dst, false)
 }
 
@@ -473,7 +520,7 @@ func (p *Player) DistributeMeleeExp(experience float64) {
 
 //EquipItem equips an item to this player, and sends inventory and equipment bonuses.
 func (p *Player) EquipItem(item *Item) {
-	reqs := definitions.Items[item.ID].Requirements
+	reqs := definitions.Item(item.ID).Requirements
 	if reqs != nil {
 		var needed string
 		for skill, lvl := range reqs {
@@ -603,6 +650,9 @@ func (p *Player) ResetAllExceptDueling() {
 	p.CloseShop()
 }
 
+//MaxFatigue The most fatigue a player can have, which the client shows as 100%.
+const MaxFatigue = 75000
+
 //Fatigue Returns the players current fatigue.
 func (p *Player) Fatigue() int {
 	return p.Attributes.VarInt("fatigue", 0)
@@ -613,6 +663,17 @@ func (p *Player) SetFatigue(i int) {
 	p.Attributes.SetVar("fatigue", i)
 }
 
+//AddFatigue Adds amount to the players fatigue, up to MaxFatigue, and updates the client about it.
+func (p *Player) AddFatigue(amount int) {
+	p.SetFatigue(int(math.Min(MaxFatigue, float64(p.Fatigue()+amount))))
+	p.SendFatigue()
+}
+
+//SendFatigue Sends the players current fatigue to its client.
+func (p *Player) SendFatigue() {
+	p.WritePacket(Fatigue(p))
+}
+
 //NearbyPlayers Returns nearby players.
 func (p *Player) NearbyPlayers() (players []*Player) {
 	for _, r := range VisibleRegionsFrom(p) {
@@ -672,7 +733,7 @@ func (p *Player) NewItems() (items []*GroundItem) {
 	for _, r := range VisibleRegionsFrom(p) {
 		r.Items.Lock()
 		for _, i := range r.Items.set {
-			if p.Near(i, p.ViewRadius() * 2) && !p.LocalItems.Contains(i) {
+			if p.Near(i, p.ViewRadius() * 2) && !p.LocalItems.Contains(i) && i.(*GroundItem).VisibleTo(p) {
 				items = append(items, i.(*GroundItem))
 			}
 		}
@@ -940,17 +1001,31 @@ func (p *Player) Destroy() {
 		if Players.Find(p) > -1 {
 			log.Debug("Unregistered:", p.Username() + "@" + p.CurrentIP())
 			p.ResetAll()
-			go DefaultPlayerService.PlayerSave(p)
 			RemovePlayer(p)
+			if l, ok := p.VarChecked("logoutLocation").(entity.Location); ok {
+				p.SetCoords(l.X(), l.Y(), true)
+			}
+			go DefaultPlayerService.PlayerSave(p)
 			return
 		}
 		log.Debug("Unregistered:", p.CurrentIP())
 	})
 }
 
+//SetLogoutLocation Makes this player get saved at l, rather than where it stands, if it logs out before
+// ClearLogoutLocation is called, e.g partway across an agility obstacle.
+func (p *Player) SetLogoutLocation(l entity.Location) {
+	p.SetVar("logoutLocation", l)
+}
+
+//ClearLogoutLocation Makes this player get saved where it stands when it logs out again.
+func (p *Player) ClearLogoutLocation() {
+	p.UnsetVar("logoutLocation")
+}
+
 func (p *Player) AtObject(object *Object) bool {
 	bounds := object.Boundaries()
-	if definitions.ScenaryObjects[object.ID].SolidityType == 2 || definitions.ScenaryObjects[object.ID].SolidityType == 3 {
+	if solidity := definitions.Scenary(object.ID).SolidityType; solidity == 2 || solidity == 3 {
 		// door types
 		return /* (!p.Collides(bounds[0]) && p.Collides(bounds[1])) && */p.WithinArea(bounds)
 	}
@@ -1066,6 +1141,7 @@ func (p *Player) Initialize() {
 	for _, fn := range LoginTriggers {
 		go fn(p)
 	}
+	resumeEffects(p)
 	p.Enqueue(playerEvents, map[string]int {"index": int(p.ServerIndex()), "ticket": int(p.AppearanceTicket())})
 }
 
@@ -1359,7 +1435,7 @@ func (p *Player) AddItem(id, amount int) {
 		defer p.SendInventory()
 	}
 	stackSize := 1
-	if definitions.Items[id].Stackable {
+	if definitions.Item(id).Stackable {
 		stackSize = amount
 	}
 	for i := 0; i < amount; i += stackSize {
@@ -1384,6 +1460,11 @@ func (p *Player) PrayerOn(idx int) {
 		p.SendPrayers()
 		return
 	}
+	if p.Skills().Current(entity.StatPrayer) <= 0 {
+		p.Message("You have run out of prayer points. Return to a church to recharge")
+		p.SendPrayers()
+		return
+	}
 	boosterPrayers := [...][3]int{
 		{0, 3, 9},
 		{1, 4, 10},
@@ -1506,14 +1587,31 @@ func (p *Player) StartCombat(defender entity.MobileEntity) {
 		}
 
 		// Paralyze Monster goes into effect right here, we just return before the npc can do anything
-		if defender.IsPlayer() && attacker.IsNpc() && defender.PrayerActivated(12) {
+		if defender.IsPlayer() && attacker.IsNpc() && defender.PrayerActivated(PrayerParalyzeMonster) {
 			return false
 		}
 
+		// when the duel allows missiles, duelists wielding a bow take their turns by shooting instead
+		if attackerp := AsPlayer(attacker); attackerp != nil && attackerp.IsDueling() && attackerp.DuelMagic() {
+			if ammo := attackerp.RangedAmmo(); ammo >= 0 {
+				hit := attackerp.Shoot(defender, ammo)
+				if defender.DamageFrom(attacker, hit, DamageRanged) {
+					return true
+				}
+				if hit > 0 {
+					inflictPoison(attacker, defender, ammo)
+				}
+				return false
+			}
+		}
+
 		nextHit := int(math.Min(float64(defender.Skills().Current(entity.StatHits)), float64(attacker.MeleeDamage(defender))))
-		if defender.DamageFrom(attacker, nextHit, 0) {
+		if defender.DamageFrom(attacker, nextHit, DamageMelee) {
 			return true
 		}
+		if nextHit > 0 {
+			meleeHitEffects(attacker, defender)
+		}
 		return false
 	})
 }
@@ -1524,29 +1622,16 @@ func (p *Player) Killed(killer entity.MobileEntity) {
 	p.PlaySound("death")
 	p.WritePacket(Death)
 
-	for i := 0; i < 14; i++ {
-		p.DeactivatePrayer(i)
-	}
-
-	for i := 0; i < 18; i++ {
-		p.Skills().SetCur(i, p.Skills().Maximum(i))
-	}
-
-	p.SendPrayers()
-	p.SendStats()
-	p.SetDirection(NorthWest)
-
 	deathItems := []*GroundItem{NewGroundItem(DefaultDrop, 1, p.X(), p.Y())}
 	if !p.IsDueling() {
-		keepCount := 0
-		if p.PrayerActivated(8) {
-			// protect item prayer
-			keepCount++
-		}
-		if !p.Skulled() {
-			keepCount += 3
+		if !p.Zone().SafeDeath() {
+			keepCount := 0
+			if !p.Skulled() {
+				keepCount += 3
+			}
+			// worked out before the prayers below are turned off, for Protect Item
+			deathItems = append(deathItems, p.Inventory.DeathDrops(keepCount)...)
 		}
-		deathItems = append(deathItems, p.Inventory.DeathDrops(keepCount)...)
 	} else {
 		p.DuelOffer.Lock.RLock()
 		for _, i := range p.DuelOffer.List {
@@ -1559,18 +1644,33 @@ func (p *Player) Killed(killer entity.MobileEntity) {
 		p.ResetDuel()
 	}
 
+	for i := 0; i < 14; i++ {
+		p.DeactivatePrayer(i)
+	}
+
+	for i := 0; i < 18; i++ {
+		p.Skills().SetCur(i, p.Skills().Maximum(i))
+	}
+	ClearEffects(p)
+
+	p.SendPrayers()
+	p.SendStats()
+	p.SetDirection(NorthWest)
+
 	if killerp := AsPlayer(killer); killerp != nil {
-		killerp.DistributeMeleeExp(p.ExperienceReward() / 4.0)
+		if p.VarInt("deathBlow", DamageMelee) == DamageRanged {
+			killerp.DistributeRangedExp(p.ExperienceReward() / 4.0)
+		} else {
+			killerp.DistributeMeleeExp(p.ExperienceReward() / 4.0)
+		}
 		killerp.Message("You have defeated " + p.Username() + "!")
 	}
+	p.UnsetVar("deathBlow")
 
 	for i, v := range deathItems {
 		// becomes universally visible on NPCs, or temporarily private otherwise
 		if i == 0 || p.Inventory.RemoveByID(v.ID, v.Amount) > -1 {
-			if killer != nil && killer.IsPlayer() {
-				v.Owner = AsPlayer(killer).Username()
-			}
-			AddItem(v)
+			DropItem(AsPlayer(killer), v)
 		} else {
 			log.Cheatf("Death item failed during removal: %v,%v owner:%v, killer:%v!\n", v.ID, v.Amount, p, killer)
 		}
@@ -1690,11 +1790,6 @@ func (p *Player) Cache(name string) interface{} {
 	return p.Attributes.VarChecked(name)
 }
 
-func (p *Player) OpenSleepScreen() {
-	p.AddState(StateSleeping)
-	p.WritePacket(SleepWord(p))
-}
-
 //Read implements an io.Reader that detects what type of connection the underlying socket is using,
 // and interprets the network byte stream accordingly.  Websockets require a lot of extra book-keeping
 // to be used like this, and as such
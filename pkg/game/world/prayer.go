@@ -0,0 +1,71 @@
+/*
+ * Copyright (c) 2020 Zachariah Knight <aeros.storkpk@gmail.com>
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted, provided that the above copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ *
+ */
+
+package world
+
+import (
+	"github.com/spkaeros/rscgo/pkg/definitions"
+	"github.com/spkaeros/rscgo/pkg/game/entity"
+)
+
+const (
+	PrayerThickSkin = iota
+	PrayerBurstOfStrength
+	PrayerClarityOfThought
+	PrayerRockSkin
+	PrayerSuperhumanStrength
+	PrayerImprovedReflexes
+	PrayerRapidRestore
+	PrayerRapidHeal
+	//PrayerProtectItem Players using this keep one more item when they die.
+	PrayerProtectItem
+	PrayerSteelSkin
+	PrayerUltimateStrength
+	PrayerIncredibleReflexes
+	//PrayerParalyzeMonster NPCs do not attack players using this, and can not hit them while fighting them.
+	PrayerParalyzeMonster
+	//PrayerProtectFromMissiles Arrows and bolts shot at players using this do no damage.
+	PrayerProtectFromMissiles
+)
+
+//prayerDrainThreshold How much the drain rates of the active prayers must add up to, over however many game ticks,
+// to use up one prayer point, for a player without any prayer bonus.
+const prayerDrainThreshold = 325
+
+//prayerBonusResistance How much each point of prayer bonus from equipment raises prayerDrainThreshold by.
+const prayerBonusResistance = 30
+
+//DrainPrayer Drains this players prayer points by as much as its active prayers use up in a game tick, and turns them
+// all off once it runs out of prayer points.  This should be called once per game tick.
+func (p *Player) DrainPrayer() {
+	drain := 0
+	for i := range p.Prayers {
+		if p.PrayerActivated(i) {
+			drain += definitions.Prayer(i).Drain
+		}
+	}
+	if drain == 0 {
+		return
+	}
+	// equipment bonuses start at 1, so a player with none has no extra resistance
+	threshold := prayerDrainThreshold + prayerBonusResistance*(p.PrayerPoints()-1)
+	drained := p.SessionCache().VarInt("prayerDrain", 0) + drain
+	for ; drained >= threshold && p.Skills().Current(entity.StatPrayer) > 0; drained -= threshold {
+		p.IncCurStat(entity.StatPrayer, -1)
+	}
+	p.SessionCache().SetVar("prayerDrain", drained)
+	if p.Skills().Current(entity.StatPrayer) <= 0 {
+		for i := range p.Prayers {
+			p.DeactivatePrayer(i)
+		}
+		p.SessionCache().UnsetVar("prayerDrain")
+		p.SendPrayers()
+		p.Message("You have run out of prayer points. Return to a church to recharge")
+	}
+}
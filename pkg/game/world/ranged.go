@@ -0,0 +1,236 @@
+/*
+ * Copyright (c) 2020 Zachariah Knight <aeros.storkpk@gmail.com>
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted, provided that the above copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ *
+ */
+
+package world
+
+import (
+	"math"
+
+	"github.com/spkaeros/rscgo/pkg/definitions"
+	"github.com/spkaeros/rscgo/pkg/game/entity"
+)
+
+//ProjectileArrow The projectile sprite the client draws for arrows and bolts.
+const ProjectileArrow = 2
+
+//arrowDropChance The percent chance that a fired arrow lands beneath its target, instead of breaking.
+const arrowDropChance = 60.0
+
+//RangedWeapon Describes a bow: how many tiles away it can shoot from, how many game ticks pass between its shots, and
+// which ammunition it is able to fire.
+type RangedWeapon struct {
+	Reach, Delay int
+	Ammo         []int
+}
+
+var (
+	bolts = []int{190, 592, 786}
+	//arrowTiers Every kind of arrow, grouped by the weakest bow able to fire them; poisoned arrows follow their base arrow.
+	arrowTiers = [][]int{
+		{11, 574, 638, 639},
+		{640, 641},
+		{642, 643},
+		{644, 645},
+		{646, 647, 723},
+	}
+)
+
+//arrowsUpTo Returns every arrow fired by bows able to fire arrows from the provided tier and below, weakest first.
+func arrowsUpTo(tier int) (arrows []int) {
+	for _, tierArrows := range arrowTiers[:tier+1] {
+		arrows = append(arrows, tierArrows...)
+	}
+	return
+}
+
+//RangedWeapons Maps the item ID of every bow to what it is able to do.
+var RangedWeapons = map[int]RangedWeapon{
+	189: {5, 3, arrowsUpTo(0)}, // Shortbow
+	188: {7, 4, arrowsUpTo(0)}, // Longbow
+	649: {5, 3, arrowsUpTo(1)}, // Oak Shortbow
+	648: {7, 4, arrowsUpTo(1)}, // Oak Longbow
+	651: {5, 3, arrowsUpTo(2)}, // Willow Shortbow
+	650: {7, 4, arrowsUpTo(2)}, // Willow Longbow
+	653: {5, 3, arrowsUpTo(3)}, // Maple Shortbow
+	652: {7, 4, arrowsUpTo(3)}, // Maple Longbow
+	655: {5, 3, arrowsUpTo(4)}, // Yew Shortbow
+	654: {7, 4, arrowsUpTo(4)}, // Yew Longbow
+	657: {5, 3, arrowsUpTo(4)}, // Magic Shortbow
+	656: {7, 4, arrowsUpTo(4)}, // Magic Longbow
+	60:  {6, 4, bolts},         // Crossbow
+	59:  {6, 4, bolts},         // Phoenix Crossbow
+}
+
+//AmmoPower Maps the item ID of every arrow and bolt to how hard it hits, on the same scale as weapon power points.
+var AmmoPower = map[int]int{
+	11: 10, 574: 10, // Bronze
+	638: 15, 639: 15, // Iron
+	640: 20, 641: 20, // Steel
+	642: 25, 643: 25, // Mithril
+	644: 30, 645: 30, // Adamantite
+	646: 35, 647: 35, // Rune
+	723: 30, // Ice
+	190: 20, 592: 20, // Crossbow bolts
+	786: 25, // Oyster pearl bolts
+}
+
+//RangedWeapon Returns the item ID of the bow this player is wielding, or -1 if it is not wielding one.
+func (p *Player) RangedWeapon() int {
+	for id := range RangedWeapons {
+		if p.Inventory.Equipped(id) {
+			return id
+		}
+	}
+	return -1
+}
+
+//RangedAmmo Returns the item ID of the strongest ammunition in this players inventory that its bow is able to fire,
+// or -1 if there is none.
+func (p *Player) RangedAmmo() int {
+	weapon, ok := RangedWeapons[p.RangedWeapon()]
+	if !ok {
+		return -1
+	}
+	for i := len(weapon.Ammo) - 1; i >= 0; i-- {
+		if p.Inventory.CountID(weapon.Ammo[i]) > 0 {
+			return weapon.Ammo[i]
+		}
+	}
+	return -1
+}
+
+//RangedAccuracy Calculates and returns the ranged accuracy of this mob, on the same scale as AttackPoints.
+func (m *Mob) RangedAccuracy() float64 {
+	return DefaultCombatFormula.RangedAccuracy(m)
+}
+
+//MaxRangedDamage Calculates and returns the max hit of this mob when firing the provided ammunition.
+func (m *Mob) MaxRangedDamage(ammo int) float64 {
+	return DefaultCombatFormula.MaxRangedDamage(m, AmmoPower[ammo])
+}
+
+//RangedDamage Calculates and returns the damage of the provided ammunition fired from the receiver mob onto the target mob.
+func (m *Mob) RangedDamage(target entity.MobileEntity, ammo int) int {
+	return RollHit(m, m.RangedAccuracy(), target.DefensePoints(), m.MaxRangedDamage(ammo))
+}
+
+//DistributeRangedExp Gives this player the experience earned from dealing ranged damage, which all goes into ranged.
+// It is scaled the same as DistributeMeleeExp, which hands out 4 times experience altogether.
+func (p *Player) DistributeRangedExp(experience float64) {
+	p.IncExp(entity.StatRanged, int(experience*4.0))
+}
+
+//Shoot Fires a single piece of ammunition from this players inventory at target, showing the projectile to everyone
+// nearby, and returns the damage it does.  Some of the fired arrows land beneath the target, where the player can pick
+// them back up.
+func (p *Player) Shoot(target entity.MobileEntity, ammo int) int {
+	if p.Inventory.RemoveByID(ammo, 1) < 0 {
+		return 0
+	}
+	p.QueueProjectile(p, target, ProjectileArrow)
+	for _, viewer := range p.NearbyPlayers() {
+		viewer.QueueProjectile(p, target, ProjectileArrow)
+	}
+	if Chance(arrowDropChance) {
+		DropItem(p, NewGroundItem(ammo, 1, target.X(), target.Y()))
+	}
+	if target.IsPlayer() && target.PrayerActivated(PrayerProtectFromMissiles) {
+		return 0
+	}
+	hit := p.RangedDamage(target, ammo)
+	return int(math.Min(float64(target.Skills().Current(entity.StatHits)), float64(hit)))
+}
+
+//canShootAt Returns true if target is still around for this player to keep shooting at.
+func (p *Player) canShootAt(target entity.MobileEntity) bool {
+	if target.Skills().Current(entity.StatHits) <= 0 || !p.Near(target, p.ViewRadius()) {
+		return false
+	}
+	if n := AsNpc(target); n != nil {
+		return !n.VarBool("removed", false)
+	}
+	if targetp := AsPlayer(target); targetp != nil && !targetp.Connected() {
+		return false
+	}
+	return p.CanAttack(target)
+}
+
+//StartRangedCombat Makes this player walk to within range and sight of target, and then keep shooting at it with the bow
+// it is wielding, every few game ticks, until it runs out of ammunition or the target dies or leaves.
+// Unlike melee combat, neither the player nor its target are locked into a fight.
+func (p *Player) StartRangedCombat(target entity.MobileEntity) {
+	weaponID := p.RangedWeapon()
+	weapon, ok := RangedWeapons[weaponID]
+	if !ok {
+		return
+	}
+	p.SetVar("targetMob", target)
+	firstShot := true
+	p.SetTickAction(func() bool {
+		if p.RangedWeapon() != weaponID || !p.canShootAt(target) {
+			return false
+		}
+		if !p.Near(target, weapon.Reach) || !p.InSight(target) {
+			if p.FinishedPath() {
+				pivotList := p.PivotTo(target)
+				if len(pivotList[0]) == 0 || len(pivotList[1]) == 0 {
+					// no reasonable direct path to target
+					p.Message("I can't reach that.")
+					return false
+				}
+				p.ResetPath()
+				p.SetCoords(pivotList[0][0], pivotList[1][0], false)
+			}
+			return true
+		}
+		p.ResetPath()
+		if CurrentTick() < p.VarInt("nextShot", 0) {
+			return true
+		}
+		ammo := p.RangedAmmo()
+		if ammo < 0 {
+			msg := "You have run out of ammo!"
+			for id := range AmmoPower {
+				if p.Inventory.CountID(id) > 0 {
+					msg = "You can't fire " + definitions.Item(id).Name + " with this " + definitions.Item(weaponID).Name
+					break
+				}
+			}
+			p.Message(msg)
+			return false
+		}
+		if firstShot {
+			firstShot = false
+			if n := AsNpc(target); n != nil {
+				for _, trigger := range NpcAtkTriggers {
+					if trigger.Check(p, n) {
+						trigger.Action(p, n)
+						return false
+					}
+				}
+			}
+			if targetp := AsPlayer(target); targetp != nil {
+				targetp.PlaySound("underattack")
+				targetp.Message("Warning! " + p.Username() + " is shooting at you!")
+				if !p.IsDueling() && !targetp.SkulledOn(p.UsernameHash()) {
+					p.SkullOn(targetp)
+				}
+			}
+		}
+		p.SetVar("nextShot", CurrentTick()+weapon.Delay)
+		hit := p.Shoot(target, ammo)
+		if target.DamageFrom(p, hit, DamageRanged) {
+			return false
+		}
+		if hit > 0 {
+			inflictPoison(p, target, ammo)
+		}
+		return true
+	})
+}
@@ -33,6 +33,15 @@ type ItemTrigger struct {
 	Action func(*Player, *Item)
 }
 
+//ItemOnItemTrigger A type that defines a callback to run when an inventory item is used on another inventory item, and
+// a predicate to decide whether or not the callback should run
+type ItemOnItemTrigger struct {
+	// Check returns true if this handler should run.
+	Check func(*Item, *Item) bool
+	// Action is the function that will run if Check returned true.
+	Action func(*Player, *Item, *Item)
+}
+
 //ItemOnPlayerTrigger A type that defines a callback to run when certain item are used on players, and a predicate to decide
 // whether or not the callback should run
 type ItemOnPlayerTrigger struct {
@@ -77,6 +86,12 @@ var InvOnBoundaryTriggers []func(player *Player, object *Object, item *Item) boo
 //InvOnObjectTriggers a list of actions to run when a player uses an inventory item on a object
 var InvOnObjectTriggers []func(player *Player, object *Object, item *Item) bool
 
+//InvOnGroundItemTriggers a list of actions to run when a player uses an inventory item on an item on the ground
+var InvOnGroundItemTriggers []func(player *Player, groundItem *GroundItem, item *Item) bool
+
+//ItemOnItemTriggers List of script callbacks to run when a player uses an inventory item on another inventory item
+var ItemOnItemTriggers []ItemOnItemTrigger
+
 //ItemTriggers List of script callbacks to run for inventory item actions
 var ItemTriggers []ItemTrigger
 
@@ -96,11 +111,13 @@ var NpcTalkList = make([]Callback, 0, 800)
 
 //var Triggers []Trigger
 
+//SpellTriggers Maps spell IDs to the script callbacks that run their effects, which are passed the *Spell being cast
 var SpellTriggers = make(map[int]Trigger)
 
 var PacketTriggers = make(map[byte]Trigger)
 
-type SpellDef map[string]interface{}
+//NpcActionTriggers List of script callbacks to run for NPC command actions, such as pickpocketing
+var NpcActionTriggers []NpcTrigger
 
 //NpcAtkTriggers List of script callbacks to run when you attack an NPC
 var NpcAtkTriggers []NpcBlockingTrigger
@@ -110,12 +127,15 @@ func Clear() {
 	ItemTriggers = ItemTriggers[:0]
 	ObjectTriggers = ObjectTriggers[:0]
 	NpcTalkList = NpcTalkList[:0]
+	NpcActionTriggers = NpcActionTriggers[:0]
 	NpcAtkTriggers = NpcAtkTriggers[:0]
 	NpcDeathTriggers = NpcDeathTriggers[:0]
 	BoundaryTriggers = BoundaryTriggers[:0]
 	LoginTriggers = LoginTriggers[:0]
 	InvOnBoundaryTriggers = InvOnBoundaryTriggers[:0]
 	InvOnObjectTriggers = InvOnObjectTriggers[:0]
+	InvOnGroundItemTriggers = InvOnGroundItemTriggers[:0]
+	ItemOnItemTriggers = ItemOnItemTriggers[:0]
 }
 
 //RunScripts Loads all of the scripts in ./scripts.  This will ignore any folders named definitions or lib.
@@ -94,6 +94,9 @@ func (s shopItemSet) Clone() shopItemSet {
 }
 
 func (s *ShopContainer) Add(name string, shop *Shop) {
+	if inventory, ok := takeRestoredShop(name); ok {
+		shop.Inventory.restore(inventory)
+	}
 	s.Lock()
 	s.set[name] = shop
 	s.Unlock()
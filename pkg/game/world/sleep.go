@@ -0,0 +1,222 @@
+/*
+ * Copyright (c) 2020 Zachariah Knight <aeros.storkpk@gmail.com>
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted, provided that the above copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ *
+ */
+
+package world
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/spkaeros/rscgo/pkg/log"
+	"github.com/spkaeros/rscgo/pkg/rand"
+	"github.com/spkaeros/rscgo/pkg/tasks"
+)
+
+const (
+	//sleepWordWidth The width of the sleep word image that the client expects, in pixels.
+	sleepWordWidth = 255
+	//sleepWordHeight The height of the sleep word image that the client expects, in pixels.
+	sleepWordHeight = 40
+	//bedFatigueRate How much fatigue sleeping in a bed takes away every game tick.
+	bedFatigueRate = 1500
+	//sleepingBagFatigueRate How much fatigue sleeping in a sleeping bag takes away every game tick.
+	sleepingBagFatigueRate = 375
+	//sleepWordRetryTicks How many game ticks a player has to wait for a new word after typing in a wrong one.
+	sleepWordRetryTicks = 3
+)
+
+//sleepWords The words that sleeping players are asked to type in to wake up.
+var sleepWords = []string{
+	"apple", "badger", "barrel", "beach", "brick", "bridge", "candle", "castle", "cheese", "cloud", "dragon", "dwarf",
+	"falcon", "feather", "forest", "garden", "goblin", "hammer", "harbor", "helmet", "honey", "island", "jungle",
+	"kettle", "knight", "ladder", "lantern", "marble", "meadow", "monkey", "needle", "orange", "paddle", "pebble",
+	"pillow", "pirate", "potato", "quill", "rabbit", "river", "rocket", "saddle", "salmon", "shield", "silver",
+	"spider", "squirrel", "stone", "sword", "thunder", "timber", "tower", "turtle", "valley", "wagon", "walrus",
+	"window", "wizard", "yellow", "zebra",
+}
+
+//sleepFont A 5x7 bitmap font of the lowercase letters a through z that sleep words are drawn with.  Each byte is one
+// row of a letter, with its 5 lowest bits being the pixels of that row from left to right.
+var sleepFont = [26][7]byte{
+	{0b00000, 0b00000, 0b01110, 0b00001, 0b01111, 0b10001, 0b01111}, // a
+	{0b10000, 0b10000, 0b10110, 0b11001, 0b10001, 0b10001, 0b11110}, // b
+	{0b00000, 0b00000, 0b01110, 0b10000, 0b10000, 0b10001, 0b01110}, // c
+	{0b00001, 0b00001, 0b01101, 0b10011, 0b10001, 0b10001, 0b01111}, // d
+	{0b00000, 0b00000, 0b01110, 0b10001, 0b11111, 0b10000, 0b01110}, // e
+	{0b00110, 0b01001, 0b01000, 0b11100, 0b01000, 0b01000, 0b01000}, // f
+	{0b00000, 0b01111, 0b10001, 0b10001, 0b01111, 0b00001, 0b01110}, // g
+	{0b10000, 0b10000, 0b10110, 0b11001, 0b10001, 0b10001, 0b10001}, // h
+	{0b00100, 0b00000, 0b01100, 0b00100, 0b00100, 0b00100, 0b01110}, // i
+	{0b00010, 0b00000, 0b00110, 0b00010, 0b00010, 0b10010, 0b01100}, // j
+	{0b10000, 0b10000, 0b10010, 0b10100, 0b11000, 0b10100, 0b10010}, // k
+	{0b01100, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b01110}, // l
+	{0b00000, 0b00000, 0b11010, 0b10101, 0b10101, 0b10001, 0b10001}, // m
+	{0b00000, 0b00000, 0b10110, 0b11001, 0b10001, 0b10001, 0b10001}, // n
+	{0b00000, 0b00000, 0b01110, 0b10001, 0b10001, 0b10001, 0b01110}, // o
+	{0b00000, 0b11110, 0b10001, 0b11110, 0b10000, 0b10000, 0b10000}, // p
+	{0b00000, 0b01111, 0b10001, 0b01111, 0b00001, 0b00001, 0b00001}, // q
+	{0b00000, 0b00000, 0b10110, 0b11001, 0b10000, 0b10000, 0b10000}, // r
+	{0b00000, 0b00000, 0b01111, 0b10000, 0b01110, 0b00001, 0b11110}, // s
+	{0b01000, 0b01000, 0b11100, 0b01000, 0b01000, 0b01001, 0b00110}, // t
+	{0b00000, 0b00000, 0b10001, 0b10001, 0b10001, 0b10011, 0b01101}, // u
+	{0b00000, 0b00000, 0b10001, 0b10001, 0b10001, 0b01010, 0b00100}, // v
+	{0b00000, 0b00000, 0b10001, 0b10001, 0b10101, 0b10101, 0b01010}, // w
+	{0b00000, 0b00000, 0b10001, 0b01010, 0b00100, 0b01010, 0b10001}, // x
+	{0b00000, 0b10001, 0b10001, 0b01111, 0b00001, 0b10001, 0b01110}, // y
+	{0b00000, 0b00000, 0b11111, 0b00010, 0b00100, 0b01000, 0b11111}, // z
+}
+
+//sleepWordImage Draws word onto a sleep word image, moving each letter around a little and scattering some noise over
+// it so that it is harder for a program to read, and returns it encoded the way that the client reads it.
+func sleepWordImage(word string) []byte {
+	var pixels [sleepWordHeight][sleepWordWidth]bool
+	const scaleX, scaleY = 3, 4
+	x := 8 + rand.Rng.Intn(sleepWordWidth-len(word)*(5*scaleX+5)-8)
+	for _, c := range word {
+		if c < 'a' || c > 'z' {
+			continue
+		}
+		top := 2 + rand.Rng.Intn(sleepWordHeight-7*scaleY-4)
+		for row, bits := range sleepFont[c-'a'] {
+			for col := 0; col < 5; col++ {
+				if bits&(1<<(4-col)) == 0 {
+					continue
+				}
+				for dy := 0; dy < scaleY; dy++ {
+					for dx := 0; dx < scaleX; dx++ {
+						pixels[top+row*scaleY+dy][x+col*scaleX+dx] = true
+					}
+				}
+			}
+		}
+		x += 5*scaleX + 2 + rand.Rng.Intn(4)
+	}
+	// a line wandering across the word, and some specks around it
+	y := rand.Rng.Intn(sleepWordHeight)
+	for x := 0; x < sleepWordWidth; x++ {
+		pixels[y][x] = !pixels[y][x]
+		if step := rand.Rng.Intn(5); step == 0 && y > 0 {
+			y--
+		} else if step == 1 && y < sleepWordHeight-1 {
+			y++
+		}
+	}
+	for i := 0; i < 80; i++ {
+		y, x := rand.Rng.Intn(sleepWordHeight), rand.Rng.Intn(sleepWordWidth)
+		pixels[y][x] = !pixels[y][x]
+	}
+
+	// The first row is sent as the lengths of alternating runs of black and white pixels, starting with black.
+	// Every row after it is sent as the lengths of runs of pixels that match the pixel above them, each followed by
+	// one pixel that does not, unless the run reaches the end of the row.
+	var data []byte
+	run, white := 0, false
+	for x := 0; x < sleepWordWidth; x++ {
+		if pixels[0][x] != white {
+			data = append(data, byte(run))
+			run, white = 0, !white
+		}
+		run++
+	}
+	data = append(data, byte(run))
+	for y := 1; y < sleepWordHeight; y++ {
+		run := 0
+		for x := 0; x < sleepWordWidth; x++ {
+			if pixels[y][x] != pixels[y-1][x] {
+				data = append(data, byte(run))
+				run = 0
+				continue
+			}
+			run++
+		}
+		if run > 0 {
+			data = append(data, byte(run))
+		}
+	}
+	return data
+}
+
+//newSleepWord Picks a new word for the sleeping player to type in, and sends the client an image of it.
+func (p *Player) newSleepWord() {
+	p.SetVar("sleepWord", sleepWords[rand.Rng.Intn(len(sleepWords))])
+	p.SetVar("sleepWordSent", time.Now())
+	p.WritePacket(SleepWord(p))
+}
+
+//OpenSleepScreen Puts the player to sleep, in a bed if bed is true or in a sleeping bag otherwise.  While asleep its
+// fatigue goes down a little every game tick, faster in a bed, and it wakes up with whatever fatigue is left once it
+// types in the word that it is shown.
+func (p *Player) OpenSleepScreen(bed bool) {
+	if p.HasState(StateSleeping) {
+		return
+	}
+	p.AddState(StateSleeping)
+	p.SetVar("sleepFatigue", p.Fatigue())
+	p.newSleepWord()
+	p.WritePacket(SleepFatigue(p))
+	rate := sleepingBagFatigueRate
+	if bed {
+		rate = bedFatigueRate
+	}
+	tasks.Schedule(1, func() bool {
+		if !p.Connected() || !p.HasState(StateSleeping) {
+			return true
+		}
+		if fatigue := p.VarInt("sleepFatigue", 0); fatigue > 0 {
+			p.SetVar("sleepFatigue", int(math.Max(0, float64(fatigue-rate))))
+			p.WritePacket(SleepFatigue(p))
+		}
+		return false
+	})
+}
+
+//AnswerSleepWord Checks the word that the sleeping player typed in.  When it is right the player wakes up with the
+// fatigue that it slept off, and otherwise it is told so, and shown a new word after a short wait.
+// Every answer is logged along with how long it took and how many the player has gotten right, to help spot bots.
+// Returns true if the player woke up.
+func (p *Player) AnswerSleepWord(answer string) bool {
+	if !p.HasState(StateSleeping) {
+		return false
+	}
+	word, ok := p.VarChecked("sleepWord").(string)
+	if !ok {
+		// still waiting on a new word after a wrong answer
+		return false
+	}
+	correct := strings.EqualFold(strings.TrimSpace(answer), word)
+	if correct {
+		p.Attributes.Inc("sleepWordsRight", 1)
+	} else {
+		p.Attributes.Inc("sleepWordsWrong", 1)
+	}
+	right, wrong := p.Attributes.VarInt("sleepWordsRight", 0), p.Attributes.VarInt("sleepWordsWrong", 0)
+	log.Cheatf("%v answered sleep word '%v' with '%v' (correct:%v) after %v; %d of %d answers correct (%.1f%%)\n", p.String(),
+		word, answer, correct, time.Since(p.VarTime("sleepWordSent")).Round(time.Millisecond), right, right+wrong,
+		float64(right)/float64(right+wrong)*100)
+	p.UnsetVar("sleepWord")
+	if !correct {
+		p.WritePacket(SleepWrong)
+		tasks.Schedule(sleepWordRetryTicks, func() bool {
+			if p.Connected() && p.HasState(StateSleeping) {
+				p.newSleepWord()
+			}
+			return true
+		})
+		return false
+	}
+	p.SetFatigue(p.VarInt("sleepFatigue", 0))
+	p.SendFatigue()
+	p.UnsetVar("sleepFatigue")
+	p.UnsetVar("sleepWordSent")
+	p.RemoveState(StateSleeping)
+	p.WritePacket(SleepClose)
+	p.Message("You wake up - feeling refreshed")
+	return true
+}
@@ -0,0 +1,444 @@
+/*
+ * Copyright (c) 2020 Zachariah Knight <aeros.storkpk@gmail.com>
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted, provided that the above copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ *
+ */
+
+package world
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/spkaeros/rscgo/pkg/config"
+	"github.com/spkaeros/rscgo/pkg/game/entity"
+	"github.com/spkaeros/rscgo/pkg/log"
+	"github.com/spkaeros/rscgo/pkg/tasks"
+)
+
+//SnapshotVersion The version of the snapshot file format that this server writes.  Snapshots of any other version are
+// refused when restoring, rather than guessed at.
+const SnapshotVersion = 1
+
+//ErrSnapshotVersion Returned when restoring a snapshot written in a format that this server does not understand.
+var ErrSnapshotVersion = errors.New("unsupported world snapshot version")
+
+type (
+	//Snapshot The dynamic state of the game world; everything that differs from what the world database spawns.
+	Snapshot struct {
+		Version int
+		Time    time.Time
+		Objects []ObjectState
+		Items   []ItemState
+		Shops   []ShopState
+		Npcs    []NpcState
+	}
+	//ObjectState An object that differs from the object spawned on its tile.  An ID of -1 means that the spawned object
+	// has been removed.  When RevertTicks is above 0, the object is a temporary replacement that turns back into
+	// RevertID after that many ticks, e.g a depleted rock, or a temporary object that is removed after that many ticks
+	// when RevertID is -1, e.g a fire.  A removed temporary object leaves one RemainsID item behind, unless it is -1.
+	ObjectState struct {
+		X, Y        int
+		Boundary    bool
+		ID          int
+		Direction   int
+		RevertID    int
+		RevertTicks int
+		RemainsID   int
+	}
+	//ItemState A ground item that was dropped during play, or a persistent item spawn that is waiting to respawn.
+	// Age is how many ticks a dropped item has been on the ground, and RespawnTicks is how many ticks are left before a
+	// persistent item comes back.
+	ItemState struct {
+		ID, Amount   int
+		X, Y         int
+		Owner        string
+		Age          int
+		Persistent   bool
+		RespawnTicks int
+	}
+	//ShopState The inventory of a shop, by the name it is kept under in Shops.
+	ShopState struct {
+		Name  string
+		Items []ShopItemState
+	}
+	//ShopItemState A single item stack in a shop inventory.
+	ShopItemState struct {
+		ID, Amount int
+	}
+	//NpcState Where an NPC is and how many hitpoints it has left.  NPCs are found again by their index, or failing
+	// that by their ID and start point.
+	NpcState struct {
+		Index, ID      int
+		StartX, StartY int
+		X, Y           int
+		Hits           int
+	}
+)
+
+//objectKey Identifies the spot an object occupies; one scenary object and one boundary can share a tile.
+type objectKey struct {
+	x, y     int
+	boundary bool
+}
+
+func keyOf(o *Object) objectKey {
+	return objectKey{o.X(), o.Y(), o.Boundary}
+}
+
+//objectSpawns The objects that the world database spawns, which snapshots compare the live objects against.
+var objectSpawns = struct {
+	sync.Mutex
+	set map[objectKey]*Spawn
+}{set: make(map[objectKey]*Spawn)}
+
+//objectReverts The original IDs of objects that have been replaced for a limited time, and the ticks they go back on,
+// keyed by the replacement objects.  Temporary objects are kept here too, with an ID of -1.
+var objectReverts = struct {
+	sync.Mutex
+	set map[*Object]objectRevert
+}{set: make(map[*Object]objectRevert)}
+
+type objectRevert struct {
+	id, tick, remains int
+}
+
+//restoredShops Shop inventories from a restored snapshot, for shops that scripts have not created yet.  They are
+// applied by Shops.Add whenever a shop by the same name turns up.
+var restoredShops = struct {
+	sync.Mutex
+	set map[string]shopItemSet
+}{set: make(map[string]shopItemSet)}
+
+//RecordObjectSpawns Remembers every object currently in the world as spawned by the world database.  This should be
+// called once, right after the object spawn locations are loaded, and before any snapshot is restored.
+func RecordObjectSpawns() {
+	objectSpawns.Lock()
+	defer objectSpawns.Unlock()
+	for _, e := range GetAllObjects() {
+		if o, ok := e.(*Object); ok {
+			objectSpawns.set[keyOf(o)] = ObjectSpawn(o)
+		}
+	}
+}
+
+func setObjectSpawn(s *Spawn) {
+	objectSpawns.Lock()
+	objectSpawns.set[objectKey{s.X, s.Y, s.Boundary}] = s
+	objectSpawns.Unlock()
+}
+
+func unsetObjectSpawn(o *Object) {
+	objectSpawns.Lock()
+	delete(objectSpawns.set, keyOf(o))
+	objectSpawns.Unlock()
+}
+
+//ReplaceObjectFor Replaces old the same way as ReplaceObject, and then puts the original object back after ticks game
+// ticks, unless the replacement has been taken out of the world by something else before then.
+func ReplaceObjectFor(old *Object, newID, ticks int) *Object {
+	object := ReplaceObject(old, newID)
+	revertObject(object, old.ID, -1, ticks)
+	return object
+}
+
+//AddObjectFor Adds o to the world, and then removes it after ticks game ticks, unless it has been taken out of the
+// world by something else before then.  When it is removed, one remainsID item is left on the ground in its place,
+// e.g the ashes of a fire, unless remainsID is -1.
+func AddObjectFor(o *Object, ticks, remainsID int) {
+	AddObject(o)
+	revertObject(o, -1, remainsID, ticks)
+}
+
+//revertObject Turns o back into an object with the provided ID after ticks game ticks, or removes it if id is -1.
+func revertObject(o *Object, id, remainsID, ticks int) {
+	objectReverts.Lock()
+	objectReverts.set[o] = objectRevert{id, int(tasks.Ticks.Load()) + ticks, remainsID}
+	objectReverts.Unlock()
+	tasks.Schedule(ticks, func() bool {
+		objectReverts.Lock()
+		delete(objectReverts.set, o)
+		objectReverts.Unlock()
+		if findObject(o.X(), o.Y(), o.Boundary) != o {
+			return true
+		}
+		if id < 0 {
+			RemoveObject(o)
+			if remainsID >= 0 {
+				AddItem(NewGroundItem(remainsID, 1, o.X(), o.Y()))
+			}
+			return true
+		}
+		ReplaceObject(o, id)
+		return true
+	})
+}
+
+//findObject Returns the scenary object or boundary at x,y, or nil if there is none.
+func findObject(x, y int, boundary bool) (object *Object) {
+	Region(x, y).Objects.Range(func(e entity.Entity) {
+		if o, ok := e.(*Object); ok && object == nil && o.X() == x && o.Y() == y && o.Boundary == boundary {
+			object = o
+		}
+	})
+	return
+}
+
+//snapshotFile Returns file, or the configured snapshot file when file is empty.
+func snapshotFile(file string) string {
+	if len(file) <= 0 {
+		return config.SnapshotFile()
+	}
+	return file
+}
+
+//TakeSnapshot Captures the dynamic state of the game world as it is right now.
+func TakeSnapshot() *Snapshot {
+	s := &Snapshot{Version: SnapshotVersion, Time: time.Now()}
+	now := int(tasks.Ticks.Load())
+
+	live := make(map[objectKey]*Object)
+	for _, e := range GetAllObjects() {
+		if o, ok := e.(*Object); ok {
+			live[keyOf(o)] = o
+		}
+	}
+	objectReverts.Lock()
+	objectSpawns.Lock()
+	for key, spawn := range objectSpawns.set {
+		if o, ok := live[key]; !ok {
+			s.Objects = append(s.Objects, ObjectState{X: key.x, Y: key.y, Boundary: key.boundary, ID: -1})
+		} else if o.ID != spawn.ID || int(o.Direction) != spawn.Direction {
+			s.Objects = append(s.Objects, objectState(o, now))
+		}
+	}
+	for key, o := range live {
+		if _, ok := objectSpawns.set[key]; !ok {
+			s.Objects = append(s.Objects, objectState(o, now))
+		}
+	}
+	objectSpawns.Unlock()
+	objectReverts.Unlock()
+
+	rangeItems(func(i *GroundItem) {
+		if !i.VarBool("persistent", false) {
+			s.Items = append(s.Items, ItemState{ID: i.ID, Amount: i.Amount, X: i.X(), Y: i.Y(), Owner: i.Owner, Age: i.VarInt("age", 0)})
+		}
+	})
+	respawningItems.Lock()
+	for i, tick := range respawningItems.set {
+		s.Items = append(s.Items, ItemState{ID: i.ID, Amount: i.Amount, X: i.X(), Y: i.Y(), Persistent: true, RespawnTicks: tick - now})
+	}
+	respawningItems.Unlock()
+
+	Shops.Range(func(shop *Shop) {
+		state := ShopState{Name: shop.Name}
+		shop.Inventory.RLock()
+		for _, item := range shop.Inventory.set {
+			state.Items = append(state.Items, ShopItemState{item.ID, item.Amount})
+		}
+		shop.Inventory.RUnlock()
+		s.Shops = append(s.Shops, state)
+	})
+
+	Npcs.RangeNpcs(func(n *NPC) bool {
+		if n.VarBool("removed", false) || n.VarBool("deleted", false) {
+			// dead NPCs come back at their spawn anyways
+			return false
+		}
+		hits := n.Skills().Current(entity.StatHits)
+		if n.X() != n.StartPoint.X() || n.Y() != n.StartPoint.Y() || hits != n.Skills().Maximum(entity.StatHits) {
+			s.Npcs = append(s.Npcs, NpcState{Index: n.ServerIndex(), ID: n.ID, StartX: n.StartPoint.X(), StartY: n.StartPoint.Y(), X: n.X(), Y: n.Y(), Hits: hits})
+		}
+		return false
+	})
+	return s
+}
+
+func objectState(o *Object, now int) ObjectState {
+	state := ObjectState{X: o.X(), Y: o.Y(), Boundary: o.Boundary, ID: o.ID, Direction: int(o.Direction)}
+	if revert, ok := objectReverts.set[o]; ok {
+		state.RevertID = revert.id
+		state.RemainsID = revert.remains
+		state.RevertTicks = revert.tick - now
+		if state.RevertTicks < 1 {
+			state.RevertTicks = 1
+		}
+	}
+	return state
+}
+
+//rangeItems Calls fn with every ground item that is in the world right now.
+func rangeItems(fn func(*GroundItem)) {
+	regionLock.RLock()
+	defer regionLock.RUnlock()
+	for _, xR := range regions {
+		for _, yR := range xR {
+			if yR != nil {
+				yR.Items.Range(func(e entity.Entity) {
+					if i, ok := e.(*GroundItem); ok {
+						fn(i)
+					}
+				})
+			}
+		}
+	}
+}
+
+//SaveSnapshot Writes a snapshot of the game world to file; an empty file name means the configured snapshot file.
+func SaveSnapshot(file string) error {
+	file = snapshotFile(file)
+	s := TakeSnapshot()
+	data, err := json.MarshalIndent(s, "", "\t")
+	if err != nil {
+		return err
+	}
+	// written beside the destination first, so that a crash mid-write can never leave a truncated snapshot behind
+	out, err := os.Create(file + ".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := out.Write(data); err != nil {
+		out.Close()
+		os.Remove(file + ".tmp")
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(file + ".tmp")
+		return err
+	}
+	if err := os.Rename(file+".tmp", file); err != nil {
+		return err
+	}
+	log.Commandf("Saved world snapshot with %d objects, %d items, %d shops and %d NPCs to '%v'\n", len(s.Objects), len(s.Items), len(s.Shops), len(s.Npcs), file)
+	return nil
+}
+
+//LoadSnapshot Reads a snapshot of the game world from file; an empty file name means the configured snapshot file.
+func LoadSnapshot(file string) (*Snapshot, error) {
+	f, err := os.Open(snapshotFile(file))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	s := &Snapshot{}
+	if err := json.NewDecoder(f).Decode(s); err != nil {
+		return nil, err
+	}
+	if s.Version != SnapshotVersion {
+		return nil, fmt.Errorf("%w: %d", ErrSnapshotVersion, s.Version)
+	}
+	return s, nil
+}
+
+//RestoreSnapshot Loads the snapshot in file and applies it to the game world.  The world should have all of its spawns
+// loaded, and RecordObjectSpawns called, before this is.
+func RestoreSnapshot(file string) error {
+	s, err := LoadSnapshot(file)
+	if err != nil {
+		return err
+	}
+	s.Apply()
+	log.Commandf("Restored world snapshot from %v, with %d objects, %d items, %d shops and %d NPCs\n", s.Time.Format(time.Stamp), len(s.Objects), len(s.Items), len(s.Shops), len(s.Npcs))
+	return nil
+}
+
+//Apply Changes the game world to match the snapshot.
+func (s *Snapshot) Apply() {
+	for _, state := range s.Objects {
+		old := findObject(state.X, state.Y, state.Boundary)
+		if state.ID < 0 {
+			if old != nil {
+				RemoveObject(old)
+			}
+			continue
+		}
+		if old != nil {
+			RemoveObject(old)
+		}
+		object := NewObject(state.ID, state.Direction, state.X, state.Y, state.Boundary)
+		AddObject(object)
+		if state.RevertTicks > 0 {
+			revertObject(object, state.RevertID, state.RemainsID, state.RevertTicks)
+		}
+	}
+
+	for _, state := range s.Items {
+		if state.Persistent {
+			if i := GetItem(state.X, state.Y, state.ID); i != nil && i.VarBool("persistent", false) {
+				i.removeFor(state.RespawnTicks)
+			}
+			continue
+		}
+		item := NewGroundItem(state.ID, state.Amount, state.X, state.Y)
+		item.Owner = state.Owner
+		item.SetVar("age", state.Age)
+		AddItem(item)
+	}
+
+	for _, state := range s.Shops {
+		inventory := make(shopItemSet, 0, len(state.Items))
+		for _, item := range state.Items {
+			inventory = append(inventory, &Item{ID: item.ID, Amount: item.Amount})
+		}
+		if Shops.Contains(state.Name) {
+			Shops.Get(state.Name).Inventory.restore(inventory)
+			continue
+		}
+		restoredShops.Lock()
+		restoredShops.set[state.Name] = inventory
+		restoredShops.Unlock()
+	}
+
+	for _, state := range s.Npcs {
+		n := findNpc(state)
+		if n == nil {
+			log.Warn("Could not find NPC from world snapshot:", state)
+			continue
+		}
+		n.Skills().SetCur(entity.StatHits, state.Hits)
+		n.SetLocation(NewLocation(state.X, state.Y), true)
+	}
+}
+
+func findNpc(state NpcState) *NPC {
+	matches := func(n *NPC) bool {
+		return n.ID == state.ID && n.StartPoint.X() == state.StartX && n.StartPoint.Y() == state.StartY && !n.VarBool("deleted", false)
+	}
+	if n := Npcs.Get(state.Index); n != nil {
+		if n, ok := n.(*NPC); ok && matches(n) {
+			return n
+		}
+	}
+	var npc *NPC
+	Npcs.RangeNpcs(func(n *NPC) bool {
+		if matches(n) {
+			npc = n
+			return true
+		}
+		return false
+	})
+	return npc
+}
+
+//takeRestoredShop Returns the restored inventory for the shop named name, if there is one, and forgets it.
+func takeRestoredShop(name string) (shopItemSet, bool) {
+	restoredShops.Lock()
+	defer restoredShops.Unlock()
+	inventory, ok := restoredShops.set[name]
+	delete(restoredShops.set, name)
+	return inventory, ok
+}
+
+func (s *ShopItems) restore(inventory shopItemSet) {
+	s.Lock()
+	s.set = inventory
+	s.Unlock()
+}
@@ -6,6 +6,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/spkaeros/rscgo/pkg/config"
 	// "github.com/spkaeros/rscgo/pkg/definitions"
 	"github.com/spkaeros/rscgo/pkg/isaac"
 	"github.com/spkaeros/rscgo/pkg/tasks"
@@ -312,8 +313,9 @@ func RemoveNpc(n *NPC) {
 	Region(n.X(), n.Y()).NPCs.Remove(n)
 }
 
-//AddItem Add a ground item to the region.
+//AddItem Add a ground item to the region, and begin its lifecycle of visibility and despawn timers.
 func AddItem(i *GroundItem) {
+	i.spawn()
 	Region(i.X(), i.Y()).Items.Add(i)
 }
 
@@ -353,6 +355,9 @@ type mapBarrier interface {
 	// just a shortcut for doing this check manually
 	Passable() bool
 
+	// returns true if projectiles fly over this barrier, even though mobs can't walk through it
+	Permeable() bool
+
 	// How many rows this barrier occupies
 	Width() int
 	// How many columns this barrier occupies
@@ -366,249 +371,72 @@ type mapBarrier interface {
 //AddObject Add an object to the region.
 func AddObject(o *Object) {
 	Region(o.X(), o.Y()).Objects.Add(o)
+	clipObject(o, true)
+}
+
+//clipObject Applies the collision masks that the object causes to the tiles that it occupies, or clears them when
+// apply is false.
+func clipObject(o *Object, apply bool) {
 	data := o.TypeData()
-	if !data.Defined() {
+	if !data.Defined() || data.Passable() {
 		return
 	}
-	if data.Passable() {
-		return
+	clip := func(x, y int, mask CollisionMask) {
+		clipTile(x, y, mask, data.Permeable(), apply)
 	}
 	if o.Boundary {
-		x,y := o.X(),o.Y()
-		areaX := (2304+x) % RegionSize
-		areaY := (1776+y - (944*((y+100)/944))) % RegionSize
+		x, y := o.X(), o.Y()
 		switch o.Direction {
-		case 0:
-			sectorFromCoords(x, y).Tiles[areaX*RegionSize+areaY] |= ClipNorth
-			if areaX+areaY > 0 {
-				sectorFromCoords(x, y-1).Tiles[areaX*RegionSize+areaY-1] |= ClipSouth
-			}
-		case 1:
-			sectorFromCoords(x, y).Tiles[areaX*RegionSize+areaY] |= ClipEast
-			if areaX > 0 || areaY >= 48 {
-				sectorFromCoords(x-1, y).Tiles[(areaX-1)*RegionSize+areaY] |= ClipWest
-			}
-		case 2:
-			sectorFromCoords(x, y).Tiles[areaX*RegionSize+areaY] |= ClipSwNe
-		case 3:
-			sectorFromCoords(x, y).Tiles[areaX*RegionSize+areaY] |= ClipSeNw
+		case 0: // Vertical wall ('| ',' |') North<->South
+			clip(x, y, ClipNorth)
+			clip(x, y-1, ClipSouth)
+		case 1: // Horizontal wall ('__','‾‾') East<->West
+			clip(x, y, ClipEast)
+			clip(x-1, y, ClipWest)
+		case 2: // Diagonal wall ('\','‾|','|_') Southwest<->Northeast
+			clip(x, y, ClipSwNe)
+		case 3: // Diagonal wall ('/','|‾','_|') Southeast<->Northwest
+			clip(x, y, ClipSeNw)
 		}
 		return
 	}
+	// type 1 is used when the object fully blocks the tile(s) that it sits on.  Marks tile as fully blocked.
+	// type 2 is used when the object mimics a boundary, e.g for gates and the like.
 	width, height := data.Width(), data.Height()
 	if o.Direction&3 != 0 {
+		// reverse measurements for directions 0(North) and 4(South), as scenary measurements
+		// are oriented vertically by default
 		width, height = height, width
 	}
 	for dx := 0; dx < width; dx++ {
 		for dy := 0; dy < height; dy++ {
-			x,y := o.X()+dx,o.Y()+dy
-			areaX := (2304+x) % RegionSize
-			areaY := (1776+y - (944*((y+100)/944))) % RegionSize
-			if len(sectorFromCoords(x, y).Tiles) <= 0 {
-				return
-			}
+			x, y := o.X()+dx, o.Y()+dy
 			if data.Solid() {
-				sectorFromCoords(x, y).Tiles[areaX*RegionSize+areaY] |= ClipFullBlock
+				clip(x, y, ClipFullBlock)
 				continue
 			}
-			switch int(o.Direction) {
+			switch o.Direction {
 			case 0: // block movement from the west
-				// Block the tiles east side
-				sectorFromCoords(x, y).Tiles[areaX*RegionSize+areaY] |= ClipEast
-				// ensure that the neighbors index is valid
-				if len(sectorFromCoords(x-1, y).Tiles) > 0 && (areaX > 0 || areaY >= RegionSize) {
-					// then block the eastern neighbors west side
-					sectorFromCoords(x-1, y).Tiles[(areaX-1)*RegionSize+areaY] |= ClipWest
-				}
+				clip(x, y, ClipEast)
+				clip(x-1, y, ClipWest)
 			case 2: // block movement from the north
-				// Block the tiles south side
-				sectorFromCoords(x, y).Tiles[areaX*RegionSize+areaY] |= ClipSouth
-				// then block the southern neighbors north side
-				sectorFromCoords(x, y+1).Tiles[areaX*RegionSize+areaY+1] |= ClipNorth
+				clip(x, y, ClipSouth)
+				clip(x, y+1, ClipNorth)
 			case 4: // block movement from the east
-				// Block the tiles west side
-				sectorFromCoords(x, y).Tiles[areaX*RegionSize+areaY] |= ClipWest
-				// then block the western neighbors east side
-				if areaX, areaY := (2304+x+1)%RegionSize, (1776+y-(944*((y+100)/944)))%RegionSize; (areaX+1)*RegionSize+areaY > 2304 {
-					sectorFromCoords(x+1, y).Tiles[areaX*RegionSize+areaY] |= ClipEast
-				}
+				clip(x, y, ClipWest)
+				clip(x+1, y, ClipEast)
 			case 6: // block movement from the south
-				// Block the tiles north side
-				sectorFromCoords(x, y).Tiles[areaX*RegionSize+areaY] |= ClipNorth
-				// ensure that the neighbors index is valid
-				if len(sectorFromCoords(x, y-1).Tiles) > 0 && areaX+areaY > 0 {
-					// then block the eastern neighbors west side
-					sectorFromCoords(x, y-1).Tiles[areaX*RegionSize+areaY-1] |= ClipSouth
-				}
+				clip(x, y, ClipNorth)
+				clip(x, y-1, ClipSouth)
 			}
 		}
 	}
-	// if !o.Boundary {
-		// scenary := definitions.ScenaryObjects[o.ID]
-		// // type 0 is used when the object causes no collisions of any sort.
-		// // type 1 is used when the object fully blocks the tile(s) that it sits on.  Marks tile as fully blocked.
-		// // type 2 is used when the object mimics a boundary, e.g for gates and the like.
-		// // type 3 is used when the object mimics an opened door-type boundary, e.g opened gates and the like.
-		// if scenary.SolidityType == 0 || scenary.SolidityType == 3 {
-			// return
-		// }
-		// width, height := scenary.Height(), scenary.Width()
-		// //if o.Direction == 0 || o.Direction == 4 {
-		// if o.Direction%4 == 0 {
-			// width, height = height, width
-		// }
-		// for x := o.X(); x < o.X()+width; x++ {
-			// for y := o.Y(); y < o.Y()+height; y++ {
-				// areaX := (2304 + x) % RegionSize
-				// areaY := (1776 + y - (944 * ((y + 100) / 944))) % RegionSize
-				// if len(sectorFromCoords(x, y).Tiles) <= 0 {
-					// log.Warning.Println("ERROR: Sector with no tiles at:" + strconv.Itoa(x) + "," + strconv.Itoa(y) + " (" + strconv.Itoa(areaX) + "," + strconv.Itoa(areaY) + "\n")
-					// return
-				// }
-				// if scenary.SolidityType == 1 {
-					// // Blocks the whole tile.  Can not walk on it from any direction
-					// sectorFromCoords(x, y).Tiles[areaX*RegionSize+areaY] |= ClipFullBlock
-					// continue
-				// }
-// 
-				// // If it's gone this far, collisionType is 2 (directional blocking, e.g gates etc)
-				// if o.Direction == byte(North) {
-					// // Block the tiles east side
-					// sectorFromCoords(x, y).Tiles[areaX*RegionSize+areaY] |= ClipEast
-					// // ensure that the neighbors index is valid
-					// if len(sectorFromCoords(x-1, y).Tiles) > 0 && (areaX > 0 || areaY >= RegionSize) {
-						// // then block the eastern neighbors west side
-						// sectorFromCoords(x-1, y).Tiles[(areaX-1)*RegionSize+areaY] |= ClipWest
-					// }
-				// } else if o.Direction == byte(West) {
-					// // Block the tiles south side
-					// sectorFromCoords(x, y).Tiles[areaX*RegionSize+areaY] |= ClipSouth
-					// // then block the southern neighbors north side
-					// sectorFromCoords(x, y+1).Tiles[areaX*RegionSize+areaY+1] |= ClipNorth
-				// } else if o.Direction == byte(South) {
-					// // Block the tiles west side
-					// sectorFromCoords(x, y).Tiles[areaX*RegionSize+areaY] |= ClipWest
-					// // then block the western neighbors east side
-					// if areaX, areaY := (2304+x+1)%RegionSize, (1776+y-(944*((y+100)/944)))%RegionSize; (areaX+1)*RegionSize+areaY > 2304 {
-						// sectorFromCoords(x+1, y).Tiles[areaX*RegionSize+areaY] |= ClipEast
-					// }
-				// } else if o.Direction == byte(East) {
-					// // Block the tiles north side
-					// sectorFromCoords(x, y).Tiles[areaX*RegionSize+areaY] |= ClipNorth
-					// // ensure that the neighbors index is valid
-					// if len(sectorFromCoords(x, y-1).Tiles) > 0 && areaX+areaY > 0 {
-						// // then block the eastern neighbors west side
-						// sectorFromCoords(x, y-1).Tiles[areaX*RegionSize+areaY-1] |= ClipSouth
-					// }
-				// }
-// 
-			// }
-		// }
-	// } else {
-		// boundary := definitions.BoundaryObjects[o.ID]
-		// if !boundary.Solid {
-			// // Doorframes and some other stuff collide with nothing.
-			// return
-		// }
-		// x, y := o.X(), o.Y()
-		// areaX := (2304 + x) % RegionSize
-		// areaY := (1776 + y - (944 * ((y + 100) / 944))) % RegionSize
-		// if len(sectorFromCoords(x, y).Tiles) <= 0 {
-			// log.Warn("ERROR: Sector with no tiles at:" + strconv.Itoa(x) + "," + strconv.Itoa(y) + " (" + strconv.Itoa(areaX) + "," + strconv.Itoa(areaY) + "\n")
-			// return
-		// }
-		// if o.Direction == 0 {
-			// sectorFromCoords(x, y).Tiles[areaX*RegionSize+areaY] |= ClipNorth
-			// if areaX+areaY > 0 {
-				// sectorFromCoords(x, y-1).Tiles[areaX*RegionSize+areaY-1] |= ClipSouth
-			// }
-		// } else if o.Direction == 1 {
-			// sectorFromCoords(x, y).Tiles[areaX*RegionSize+areaY] |= ClipEast
-			// if areaX > 0 || areaY >= 48 {
-				// sectorFromCoords(x-1, y).Tiles[(areaX-1)*RegionSize+areaY] |= ClipWest
-			// }
-		// } else if o.Direction == 2 {
-			// sectorFromCoords(x, y).Tiles[areaX*RegionSize+areaY] |= ClipSwNe
-		// } else if o.Direction == 3 {
-			// sectorFromCoords(x, y).Tiles[areaX*RegionSize+areaY] |= ClipSeNw
-		// }
-	// }
 }
 
 //RemoveObject SetRegionRemoved an object from the region.
 func RemoveObject(o *Object) {
 	Region(o.X(), o.Y()).Objects.Remove(o)
-	data := o.TypeData()
-	if data.Passable() {
-		return
-	}
-	if !o.Boundary {
-		// scenary := definitions.ScenaryObjects[o.ID]
-		// type 0 is used when the object causes no collisions of any sort.
-		// type 1 is used when the object fully blocks the tile(s) that it sits on.  Marks tile as fully blocked.
-		// type 2 is used when the object mimics a boundary, e.g for gates and the like.
-		// type 3 is used when the object mimics an opened door-type boundary, e.g opened gates and the like.
-		width, height := data.Width(), data.Height()
-		//if o.Direction == byte(North) || o.Direction == byte(South) {
-		if o.Direction&3 != 0 {
-			// reverse measurements for directions 0(North) and 4(South), as scenary measurements
-			// are oriented vertically by default
-			width,height = height,width
-		}
-		for dx := 0; dx < width; dx++ {
-			for dy := 0; dy < height; dy++ {
-				x,y := o.X()+dx,o.Y()+dy
-				areaX := (2304 + x) % RegionSize
-				areaY := (1776 + y - (944 * ((y + 100) / 944))) % RegionSize
-				if data.Solid() {
-					// This indicates a solid object.  Impassable and blocks the whole tile.
-					sectorFromCoords(x, y).Tiles[areaX*RegionSize+areaY] &= ^ClipFullBlock
-					continue
-				}
-				switch o.Direction {
-				case 0:
-					sectorFromCoords(x, y).Tiles[areaX*RegionSize+areaY] &= ^ClipEast
-					if sectorFromCoords(x-1, y) != nil {
-						sectorFromCoords(x-1, y).Tiles[(areaX-1)*RegionSize+areaY] &= ^ClipWest
-					}
-				case 2:
-					sectorFromCoords(x, y).Tiles[areaX*RegionSize+areaY] &= ^ClipSouth
-					if sectorFromCoords(x, y+1) != nil {
-						sectorFromCoords(x, y+1).Tiles[areaX*RegionSize+areaY+1] &= ^ClipNorth
-					}
-				case 4:
-					sectorFromCoords(x, y).Tiles[areaX*RegionSize+areaY] &= ^ClipWest
-					if sectorFromCoords(x+1, y) != nil {
-						sectorFromCoords(x+1, y).Tiles[(areaX+1)*RegionSize+areaY] &= ^ClipEast
-					}
-				case 6:
-					sectorFromCoords(x, y).Tiles[areaX*RegionSize+areaY] &= ^ClipNorth
-					if sectorFromCoords(x, y-1) != nil {
-						sectorFromCoords(x, y-1).Tiles[areaX*RegionSize+areaY-1] &= ^ClipSouth
-					}
-				}
-			}
-		}
-	} else {
-		x, y := o.X(), o.Y()
-		areaX := (2304 + x) % RegionSize
-		areaY := (1776 + y - (944 * ((y + 100) / 944))) % RegionSize
-		if o.Direction == 0 { // Vertical wall ('| ',' |') North<->South
-			sectorFromCoords(x, y).Tiles[areaX*RegionSize+areaY] &= ^ClipNorth
-			if areaX+areaY > 0 {
-				sectorFromCoords(x, y-1).Tiles[areaX*RegionSize+areaY-1] &= ^ClipSouth
-			}
-		} else if o.Direction == 1 { // Horizontal wall ('__','‾‾') East<->West
-			sectorFromCoords(x, y).Tiles[areaX*RegionSize+areaY] &= ^ClipEast
-			if areaX > 0 || areaY >= 48 {
-				sectorFromCoords(x-1, y).Tiles[(areaX-1)*RegionSize+areaY] &= ^ClipWest
-			}
-		} else if o.Direction == 2 { // Diagonal wall ('\','‾|','|_') Southwest<->Northeast
-			sectorFromCoords(x, y).Tiles[areaX*RegionSize+areaY] &= ^ClipSwNe
-		} else if o.Direction == 3 { // Diagonal wall ('/','|‾','_|') Southeast<->Northwest
-			sectorFromCoords(x, y).Tiles[areaX*RegionSize+areaY] &= ^ClipSeNw
-		}
-	}
+	clipObject(o, false)
 }
 
 //ReplaceObject Replaces old with a new game object with all of the same characteristics, except it's ID set to newID.
@@ -707,6 +535,20 @@ func NpcVisibleFrom(id, x, y int) *NPC {
 	return npc
 }
 
+//NpcsWatching Returns every NPC within radius tiles of x,y that has a clear line of sight to it.
+func NpcsWatching(x, y, radius int) (npcs []*NPC) {
+	point := NewLocation(x, y)
+	for _, r := range VisibleRegions(x, y) {
+		r.NPCs.RangeNpcs(func(n *NPC) bool {
+			if n.LongestDelta(point) <= radius && LineOfSight(n.X(), n.Y(), x, y) {
+				npcs = append(npcs, n)
+			}
+			return false
+		})
+	}
+	return npcs
+}
+
 var regionLock = sync.RWMutex{}
 // 
 // func init() {
@@ -786,6 +628,10 @@ func BoundedChance(percent float64, minPercent, maxPercent float64) bool {
 
 //ChanceByte Grabs a single 8-bit unsigned byte out of the rscgo/rand pkg, and returns true if it's less than or equals the provided threshold.
 func ChanceByte(threshold int) bool {
+	if threshold > 255 {
+		// every byte passes; this would otherwise overflow to 0
+		return true
+	}
 	return rscRand.Byte() <= uint8(threshold)
 }
 
@@ -817,7 +663,9 @@ func Statistical(rng *isaac.ISAAC, options IntProbabilitys) int {
 	for i, p := range options {
 		prob += p
 		if rolled <= prob {
-			log.Debug("Chose", i, "; hit", rolled, "probability =", prob, "/", total)
+			if config.Verbosity >= 2 {
+				log.Debug("Chose", i, "; hit", rolled, "probability =", prob, "/", total)
+			}
 			return i
 		}
 	}
@@ -0,0 +1,302 @@
+/*
+ * Copyright (c) 2020 Zachariah Knight <aeros.storkpk@gmail.com>
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted, provided that the above copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ *
+ */
+
+package world
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/spkaeros/rscgo/pkg/log"
+)
+
+//SpawnKind Identifies which spawn location table a Spawn belongs to.
+type SpawnKind int
+
+const (
+	//SpawnObject is a row of the game_object_locations table.
+	SpawnObject SpawnKind = iota
+	//SpawnNpc is a row of the npc_locations table.
+	SpawnNpc
+	//SpawnItem is a row of the item_locations table.
+	SpawnItem
+)
+
+//MaxUndoDepth The most world edits that are remembered for each editor.  Older edits fall off of the bottom of the stack.
+const MaxUndoDepth = 64
+
+//Spawn Describes a single persistent spawn location, as it is stored in the world database.
+type Spawn struct {
+	Kind SpawnKind
+	ID   int
+	X, Y int
+	// Direction and Boundary are only meaningful to object spawns.
+	Direction int
+	Boundary  bool
+	// MinX, MaxX, MinY and MaxY describe the wander bounds of NPC spawns.
+	MinX, MaxX, MinY, MaxY int
+	// Amount and Respawn are only meaningful to ground item spawns.
+	Amount, Respawn int
+}
+
+func (s *Spawn) String() string {
+	if s == nil {
+		return "nothing"
+	}
+	switch s.Kind {
+	case SpawnObject:
+		if s.Boundary {
+			return fmt.Sprintf("boundary{id:%d, dir:%d} at %d,%d", s.ID, s.Direction, s.X, s.Y)
+		}
+		return fmt.Sprintf("object{id:%d, dir:%d} at %d,%d", s.ID, s.Direction, s.X, s.Y)
+	case SpawnNpc:
+		return fmt.Sprintf("npc{id:%d, bounds:(%d,%d)-(%d,%d)} at %d,%d", s.ID, s.MinX, s.MinY, s.MaxX, s.MaxY, s.X, s.Y)
+	case SpawnItem:
+		return fmt.Sprintf("item{id:%d, amount:%d, respawn:%d} at %d,%d", s.ID, s.Amount, s.Respawn, s.X, s.Y)
+	}
+	return "unknown spawn"
+}
+
+//NewObjectSpawn Returns a new spawn for a scenary or boundary object.
+func NewObjectSpawn(id, direction, x, y int, boundary bool) *Spawn {
+	return &Spawn{Kind: SpawnObject, ID: id, X: x, Y: y, Direction: direction, Boundary: boundary}
+}
+
+//NewNpcSpawn Returns a new spawn for an NPC that wanders within the provided bounds.
+func NewNpcSpawn(id, startX, startY, minX, maxX, minY, maxY int) *Spawn {
+	return &Spawn{Kind: SpawnNpc, ID: id, X: startX, Y: startY, MinX: minX, MaxX: maxX, MinY: minY, MaxY: maxY}
+}
+
+//NewItemSpawn Returns a new spawn for a persistent ground item that respawns respawn seconds after being picked up.
+func NewItemSpawn(id, amount, x, y, respawn int) *Spawn {
+	return &Spawn{Kind: SpawnItem, ID: id, X: x, Y: y, Amount: amount, Respawn: respawn}
+}
+
+//ObjectSpawn Returns the spawn describing the provided object.
+func ObjectSpawn(o *Object) *Spawn {
+	return &Spawn{Kind: SpawnObject, ID: o.ID, X: o.X(), Y: o.Y(), Direction: int(o.Direction), Boundary: o.Boundary}
+}
+
+//NpcSpawn Returns the spawn describing where the provided NPC spawns and wanders.
+func NpcSpawn(n *NPC) *Spawn {
+	return &Spawn{Kind: SpawnNpc, ID: n.ID, X: n.StartPoint.X(), Y: n.StartPoint.Y(),
+		MinX: n.Boundaries[0].X(), MinY: n.Boundaries[0].Y(), MaxX: n.Boundaries[1].X(), MaxY: n.Boundaries[1].Y()}
+}
+
+//ItemSpawn Returns the spawn describing the provided persistent ground item.
+func ItemSpawn(i *GroundItem) *Spawn {
+	return &Spawn{Kind: SpawnItem, ID: i.ID, X: i.X(), Y: i.Y(), Amount: i.Amount, Respawn: i.VarInt("respawnTime", 10)}
+}
+
+//WorldEdit A single reversible change to the persistent spawns of the game world.
+// Before is the spawn being taken away and After is the spawn taking its place.  A nil Before means the edit places a
+// brand new spawn, and a nil After means the edit deletes a spawn.  When both are set, they must be of the same kind.
+type WorldEdit struct {
+	Editor        string
+	Before, After *Spawn
+	Time          time.Time
+}
+
+//Inverse Returns a new edit that will undo the receiver edit.
+func (e *WorldEdit) Inverse() *WorldEdit {
+	return &WorldEdit{Editor: e.Editor, Before: e.After, After: e.Before, Time: time.Now()}
+}
+
+func (e *WorldEdit) String() string {
+	switch {
+	case e.Before == nil:
+		return "placed " + e.After.String()
+	case e.After == nil:
+		return "deleted " + e.Before.String()
+	}
+	return "changed " + e.Before.String() + " to " + e.After.String()
+}
+
+//SpawnService Persists world edits to the backing store of the world's spawn locations.
+// SaveEdit must apply the entire edit atomically; if it returns an error, nothing should have been written.
+type SpawnService interface {
+	SaveEdit(*WorldEdit) error
+}
+
+//DefaultSpawnService The service used to persist world edits made in-game.
+var DefaultSpawnService SpawnService
+
+var (
+	//ErrNoSpawnService is returned when world edits are attempted without a spawn service to persist them.
+	ErrNoSpawnService = errors.New("no spawn service is available to save world edits")
+	//ErrSpawnNotFound is returned when the spawn an edit removes can not be found in the game world.
+	ErrSpawnNotFound = errors.New("could not find the spawn being edited")
+	//ErrTileOccupied is returned when an object is placed onto a tile that already holds an object.
+	ErrTileOccupied = errors.New("there is already an object on that tile")
+	//ErrNothingToUndo is returned when an editor has no edits left to undo.
+	ErrNothingToUndo = errors.New("there are no edits to undo")
+)
+
+var editHistory = struct {
+	stacks map[uint64][]*WorldEdit
+	sync.Mutex
+}{stacks: make(map[uint64][]*WorldEdit)}
+
+//EditMode Returns true if the player has world edit mode enabled.
+func (p *Player) EditMode() bool {
+	return p.VarBool("worldEdit", false)
+}
+
+//ToggleEditMode Switches world edit mode on or off for the player, and returns the new mode.
+// Only administrators may enable edit mode.
+func (p *Player) ToggleEditMode() bool {
+	if p.EditMode() {
+		p.UnsetVar("worldEdit")
+		return false
+	}
+	if p.Rank() != 2 {
+		return false
+	}
+	p.SetVar("worldEdit", true)
+	return true
+}
+
+//ApplyEdit Saves the edit using DefaultSpawnService, and once it has been saved, applies it to the live game world.
+// On success, the edit is pushed onto the editor's undo stack and written to the command audit log.
+func ApplyEdit(editor *Player, edit *WorldEdit) error {
+	if err := applyEdit(edit); err != nil {
+		return err
+	}
+	editHistory.Lock()
+	stack := append(editHistory.stacks[editor.UsernameHash()], edit)
+	if len(stack) > MaxUndoDepth {
+		stack = stack[len(stack)-MaxUndoDepth:]
+	}
+	editHistory.stacks[editor.UsernameHash()] = stack
+	editHistory.Unlock()
+	log.Commandf("'%v' %v\n", edit.Editor, edit)
+	return nil
+}
+
+//UndoEdit Reverts the most recent edit made by the editor, and returns the edit that was reverted.
+func UndoEdit(editor *Player) (*WorldEdit, error) {
+	editHistory.Lock()
+	defer editHistory.Unlock()
+	stack := editHistory.stacks[editor.UsernameHash()]
+	if len(stack) == 0 {
+		return nil, ErrNothingToUndo
+	}
+	edit := stack[len(stack)-1]
+	undo := edit.Inverse()
+	undo.Editor = editor.Username()
+	if err := applyEdit(undo); err != nil {
+		return nil, err
+	}
+	editHistory.stacks[editor.UsernameHash()] = stack[:len(stack)-1]
+	log.Commandf("'%v' undid edit: %v\n", undo.Editor, undo)
+	return edit, nil
+}
+
+//PlaceSpawn Places a brand new persistent spawn into the world on behalf of the editor.
+func PlaceSpawn(editor *Player, s *Spawn) error {
+	return ApplyEdit(editor, &WorldEdit{Editor: editor.Username(), After: s, Time: time.Now()})
+}
+
+//ReplaceSpawn Replaces an existing persistent spawn in the world with another one on behalf of the editor.
+// Moving, rotating and resizing spawns are all done through this.
+func ReplaceSpawn(editor *Player, old, s *Spawn) error {
+	return ApplyEdit(editor, &WorldEdit{Editor: editor.Username(), Before: old, After: s, Time: time.Now()})
+}
+
+//DeleteSpawn Removes an existing persistent spawn from the world on behalf of the editor.
+func DeleteSpawn(editor *Player, s *Spawn) error {
+	return ApplyEdit(editor, &WorldEdit{Editor: editor.Username(), Before: s, Time: time.Now()})
+}
+
+func applyEdit(edit *WorldEdit) error {
+	if DefaultSpawnService == nil {
+		return ErrNoSpawnService
+	}
+	if edit.Before != nil && edit.After != nil && edit.Before.Kind != edit.After.Kind {
+		return errors.New("can not replace a spawn with a spawn of another kind")
+	}
+	// Look everything up before saving anything, so that the database is never changed by an edit that can't go live.
+	var old interface{}
+	if edit.Before != nil {
+		if old = findSpawn(edit.Before); old == nil {
+			return ErrSpawnNotFound
+		}
+	}
+	if edit.After != nil && edit.After.Kind == SpawnObject {
+		if o := GetObject(edit.After.X, edit.After.Y); o != nil && o != old {
+			return ErrTileOccupied
+		}
+	}
+	if err := DefaultSpawnService.SaveEdit(edit); err != nil {
+		return err
+	}
+	if old != nil {
+		despawn(old)
+	}
+	if edit.After != nil {
+		spawn(edit.After)
+	}
+	return nil
+}
+
+//findSpawn Returns the live entity that was spawned by s, or nil if there is none.
+func findSpawn(s *Spawn) interface{} {
+	switch s.Kind {
+	case SpawnObject:
+		if o := GetObject(s.X, s.Y); o != nil && o.ID == s.ID && o.Boundary == s.Boundary && int(o.Direction) == s.Direction {
+			return o
+		}
+	case SpawnNpc:
+		var npc *NPC
+		Npcs.RangeNpcs(func(n *NPC) bool {
+			if !n.VarBool("deleted", false) && *NpcSpawn(n) == *s {
+				npc = n
+				return true
+			}
+			return false
+		})
+		if npc != nil {
+			return npc
+		}
+	case SpawnItem:
+		if i := GetItem(s.X, s.Y, s.ID); i != nil && i.VarBool("persistent", false) && *ItemSpawn(i) == *s {
+			return i
+		}
+	}
+	return nil
+}
+
+func despawn(entity interface{}) {
+	switch e := entity.(type) {
+	case *Object:
+		RemoveObject(e)
+		unsetObjectSpawn(e)
+	case *NPC:
+		// NPCs stay in Npcs so that server indexes are never reused; the flag keeps them from respawning
+		e.SetVar("deleted", true)
+		e.Remove()
+	case *GroundItem:
+		e.UnsetVar("persistent")
+		e.Remove()
+	}
+}
+
+func spawn(s *Spawn) {
+	switch s.Kind {
+	case SpawnObject:
+		AddObject(NewObject(s.ID, s.Direction, s.X, s.Y, s.Boundary))
+		setObjectSpawn(s)
+	case SpawnNpc:
+		AddNpc(NewNpc(s.ID, s.X, s.Y, s.MinX, s.MaxX, s.MinY, s.MaxY))
+	case SpawnItem:
+		AddItem(NewPersistentGroundItem(s.ID, s.Amount, s.X, s.Y, s.Respawn))
+	}
+}
@@ -0,0 +1,156 @@
+/*
+ * Copyright (c) 2020 Zachariah Knight <aeros.storkpk@gmail.com>
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted, provided that the above copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ *
+ */
+
+package world
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/spkaeros/rscgo/pkg/game/entity"
+)
+
+const (
+	//ZonePvp Players are allowed to attack each other here.
+	ZonePvp = 1 << iota
+	//ZoneMulti More than one mob may attack the same target at once here.
+	ZoneMulti
+	//ZoneNoTeleport Teleport spells do not work here.
+	ZoneNoTeleport
+	//ZoneSafeDeath Players that die here keep all of their items.
+	ZoneSafeDeath
+	//ZoneMembers Only members worlds allow players to walk in here.
+	ZoneMembers
+)
+
+//Zone A named area of the world, with flags changing the rules that apply to the players inside of it.
+// Its area is a rectangle when it has 2 points, as the inclusive corners of it, or a polygon when it has more.
+//
+// Zones can overlap, in which case only the one with the highest priority applies.
+type Zone struct {
+	ID       int
+	Name     string
+	Flags    int
+	//Levels How many combat levels apart players in this zone may be to attack each other, or 0 to use the wilderness
+	// level of wherever they stand.
+	Levels   int
+	Priority int
+	Points   []Location
+}
+
+//OpenWorld The zone that applies wherever no other zone covers, with none of its flags set.
+var OpenWorld = &Zone{ID: -1, Name: "Open world"}
+
+var zones = struct {
+	list []*Zone
+	sync.RWMutex
+}{}
+
+//LoadZones Replaces every zone in the world with the provided zones.
+func LoadZones(list []*Zone) {
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Priority > list[j].Priority
+	})
+	zones.Lock()
+	defer zones.Unlock()
+	zones.list = list
+}
+
+//Zones Returns every zone in the world, highest priority first.
+func Zones() []*Zone {
+	zones.RLock()
+	defer zones.RUnlock()
+	return zones.list
+}
+
+//ZoneAt Returns the zone with the highest priority covering the provided location, or OpenWorld if none do.
+func ZoneAt(l entity.Location) *Zone {
+	for _, z := range Zones() {
+		if z.Contains(l) {
+			return z
+		}
+	}
+	return OpenWorld
+}
+
+//Contains Returns true if the provided location is inside of this zone.
+func (z *Zone) Contains(l entity.Location) bool {
+	x, y := l.X(), l.Y()
+	if len(z.Points) == 2 {
+		minX, maxX := z.Points[0].X(), z.Points[1].X()
+		minY, maxY := z.Points[0].Y(), z.Points[1].Y()
+		if minX > maxX {
+			minX, maxX = maxX, minX
+		}
+		if minY > maxY {
+			minY, maxY = maxY, minY
+		}
+		return x >= minX && x <= maxX && y >= minY && y <= maxY
+	}
+
+	// even-odd rule, casting a ray from the middle of the tile
+	px, py := float64(x)+0.5, float64(y)+0.5
+	inside := false
+	for i, j := 0, len(z.Points)-1; i < len(z.Points); j, i = i, i+1 {
+		xi, yi := float64(z.Points[i].X()), float64(z.Points[i].Y())
+		xj, yj := float64(z.Points[j].X()), float64(z.Points[j].Y())
+		if (yi > py) != (yj > py) && px < (xj-xi)*(py-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+//Has Returns true if every one of the provided flags is set on this zone.
+func (z *Zone) Has(flags int) bool {
+	return z.Flags&flags == flags
+}
+
+//Pvp Returns true if players are allowed to attack each other in this zone.
+func (z *Zone) Pvp() bool {
+	return z.Has(ZonePvp)
+}
+
+//Multi Returns true if more than one mob may attack the same target at once in this zone.
+func (z *Zone) Multi() bool {
+	return z.Has(ZoneMulti)
+}
+
+//NoTeleport Returns true if teleport spells do not work in this zone.
+func (z *Zone) NoTeleport() bool {
+	return z.Has(ZoneNoTeleport)
+}
+
+//SafeDeath Returns true if players that die in this zone keep all of their items.
+func (z *Zone) SafeDeath() bool {
+	return z.Has(ZoneSafeDeath)
+}
+
+//Members Returns true if only members worlds allow players into this zone.
+func (z *Zone) Members() bool {
+	return z.Has(ZoneMembers)
+}
+
+//LevelRange Returns how many combat levels apart players standing at the provided location in this zone may be to
+// attack each other.
+func (z *Zone) LevelRange(l entity.Location) int {
+	if z.Levels > 0 {
+		return z.Levels
+	}
+	return l.Wilderness()
+}
+
+func (z *Zone) String() string {
+	return z.Name
+}
+
+//Zone Returns the zone that applies to this mob where it stands.
+func (m *Mob) Zone() *Zone {
+	return ZoneAt(m)
+}
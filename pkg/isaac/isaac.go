@@ -104,11 +104,11 @@ func (r *ISAAC) Uint64() (number uint64) {
 	return uint64(r.Uint32()) << 32 | uint64(r.Uint32())
 }
 
-//Int63 Returns the next 8 bytes as a long integer from the ISAAC CSPRNG receiver instance.
+//Int63 Returns the next 8 bytes as a long integer from the ISAAC CSPRNG receiver instance, with the sign bit cleared.
+// math/rand.Source requires this to be non-negative; a *rand.Rand built on top of this would otherwise generate
+// floats below 0 half of the time.
 func (r *ISAAC) Int63() (number int64) {
-	// return int64(r.Uint64())<<1>>1
-	// return (int64(r.Int31()) << 32) | int64(r.Uint32()) & 0x7FFFFFFFFFFFFFFF
-	return int64(r.Uint64())
+	return int64(r.Uint64() & 0x7FFFFFFFFFFFFFFF)
 }
 
 //Uint32 Returns the next 4 bytes as an integer from the ISAAC CSPRNG receiver instance.
@@ -0,0 +1,367 @@
+/*
+ * Copyright (c) 2020 Zachariah Knight <aeros.storkpk@gmail.com>
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted, provided that the above copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ *
+ */
+
+package jag
+
+import (
+	"bytes"
+	"container/heap"
+	"sort"
+)
+
+// The standard library only decompresses bzip2, so this is a small bzip2 compressor, just good enough to make data
+// that the client can decompress.  It uses the smallest block size (the 'BZh1' header that the client expects),
+// one Huffman table for the whole block, and no randomization.
+
+const (
+	// maxBlockSize is the most run-length encoded bytes a level 1 block may hold, minus the slack bzip2 itself leaves.
+	maxBlockSize = 100000 - 19
+	// maxCodeLength is the longest Huffman code we will assign; the format allows 20, but reference decoders expect 17.
+	maxCodeLength = 17
+	// groupSize is how many symbols are coded between selectors.
+	groupSize = 50
+	runA      = 0
+	runB      = 1
+)
+
+var crcTable [256]uint32
+
+func init() {
+	for i := range crcTable {
+		c := uint32(i) << 24
+		for j := 0; j < 8; j++ {
+			if c&0x80000000 != 0 {
+				c = c<<1 ^ 0x04c11db7
+			} else {
+				c <<= 1
+			}
+		}
+		crcTable[i] = c
+	}
+}
+
+func blockCrc(data []byte) uint32 {
+	crc := ^uint32(0)
+	for _, b := range data {
+		crc = crc<<8 ^ crcTable[byte(crc>>24)^b]
+	}
+	return ^crc
+}
+
+type bitWriter struct {
+	buf   bytes.Buffer
+	bits  uint64
+	count uint
+}
+
+func (w *bitWriter) write(n uint, v uint64) {
+	w.bits = w.bits<<n | v&(1<<n-1)
+	w.count += n
+	for w.count >= 8 {
+		w.count -= 8
+		w.buf.WriteByte(byte(w.bits >> w.count))
+	}
+}
+
+func (w *bitWriter) flush() []byte {
+	if w.count > 0 {
+		w.buf.WriteByte(byte(w.bits << (8 - w.count)))
+		w.count = 0
+	}
+	return w.buf.Bytes()
+}
+
+//compress Returns data compressed into a complete bzip2 stream, including its 'BZh1' header.
+func compress(data []byte) []byte {
+	w := &bitWriter{}
+	w.write(32, 'B'<<24|'Z'<<16|'h'<<8|'1')
+	var streamCrc uint32
+	for len(data) > 0 {
+		block, n := runLengthEncode(data)
+		crc := blockCrc(data[:n])
+		streamCrc = (streamCrc<<1 | streamCrc>>31) ^ crc
+		writeBlock(w, block, crc)
+		data = data[n:]
+	}
+	w.write(24, 0x177245)
+	w.write(24, 0x385090)
+	w.write(32, uint64(streamCrc))
+	return w.flush()
+}
+
+//runLengthEncode Applies the initial run-length encoding to as much of data as fits in one block.  Runs of 4 to 255
+// identical bytes are written as 4 bytes followed by a count of the remaining repetitions.
+// Returns the encoded block, and how many bytes of data went into it.
+func runLengthEncode(data []byte) ([]byte, int) {
+	block := make([]byte, 0, maxBlockSize)
+	i := 0
+	for i < len(data) {
+		run := 1
+		for i+run < len(data) && run < 255 && data[i+run] == data[i] {
+			run++
+		}
+		size := run
+		if run >= 4 {
+			size = 5
+		}
+		if len(block)+size > maxBlockSize {
+			break
+		}
+		if run >= 4 {
+			block = append(block, data[i], data[i], data[i], data[i], byte(run-4))
+		} else {
+			for j := 0; j < run; j++ {
+				block = append(block, data[i])
+			}
+		}
+		i += run
+	}
+	return block, i
+}
+
+//sortRotations Returns the starting offset of every rotation of data, in sorted order, using prefix doubling.
+func sortRotations(data []byte) []int {
+	n := len(data)
+	rotations := make([]int, n)
+	rank := make([]int, n)
+	next := make([]int, n)
+	for i := range rotations {
+		rotations[i] = i
+		rank[i] = int(data[i])
+	}
+	for k := 1; ; k <<= 1 {
+		less := func(a, b int) bool {
+			if rank[a] != rank[b] {
+				return rank[a] < rank[b]
+			}
+			return rank[(a+k)%n] < rank[(b+k)%n]
+		}
+		sort.Slice(rotations, func(i, j int) bool {
+			return less(rotations[i], rotations[j])
+		})
+		next[rotations[0]] = 0
+		for i := 1; i < n; i++ {
+			next[rotations[i]] = next[rotations[i-1]]
+			if less(rotations[i-1], rotations[i]) {
+				next[rotations[i]]++
+			}
+		}
+		copy(rank, next)
+		// Once every rank is unique or the prefixes compared cover the whole block, the order is final.
+		if rank[rotations[n-1]] == n-1 || k >= n {
+			break
+		}
+	}
+	return rotations
+}
+
+func writeBlock(w *bitWriter, block []byte, crc uint32) {
+	// Burrows-Wheeler transform
+	rotations := sortRotations(block)
+	n := len(block)
+	bwt := make([]byte, n)
+	origPtr := 0
+	for i, r := range rotations {
+		if r == 0 {
+			origPtr = i
+		}
+		bwt[i] = block[(r+n-1)%n]
+	}
+
+	var inUse [256]bool
+	for _, b := range bwt {
+		inUse[b] = true
+	}
+	var unseqToSeq [256]byte
+	var mtf []byte
+	for i, used := range inUse {
+		if used {
+			unseqToSeq[i] = byte(len(mtf))
+			mtf = append(mtf, byte(len(mtf)))
+		}
+	}
+	eob := len(mtf) + 1
+	alphaSize := eob + 1
+
+	// Move-to-front transform, with runs of zeroes coded with RUNA and RUNB symbols
+	symbols := make([]uint16, 0, n+1)
+	zeroes := 0
+	flushZeroes := func() {
+		for zeroes--; ; zeroes = (zeroes - 2) / 2 {
+			if zeroes&1 != 0 {
+				symbols = append(symbols, runB)
+			} else {
+				symbols = append(symbols, runA)
+			}
+			if zeroes < 2 {
+				break
+			}
+		}
+		zeroes = 0
+	}
+	for _, b := range bwt {
+		seq := unseqToSeq[b]
+		j := bytes.IndexByte(mtf, seq)
+		if j == 0 {
+			zeroes++
+			continue
+		}
+		if zeroes > 0 {
+			flushZeroes()
+		}
+		copy(mtf[1:j+1], mtf[:j])
+		mtf[0] = seq
+		symbols = append(symbols, uint16(j+1))
+	}
+	if zeroes > 0 {
+		flushZeroes()
+	}
+	symbols = append(symbols, uint16(eob))
+
+	freqs := make([]int, alphaSize)
+	for _, s := range symbols {
+		freqs[s]++
+	}
+	lengths := codeLengths(freqs)
+	codes := assignCodes(lengths)
+
+	w.write(24, 0x314159)
+	w.write(24, 0x265359)
+	w.write(32, uint64(crc))
+	w.write(1, 0)
+	w.write(24, uint64(origPtr))
+	var ranges uint64
+	for i := 0; i < 16; i++ {
+		for j := 0; j < 16; j++ {
+			if inUse[i*16+j] {
+				ranges |= 1 << (15 - uint(i))
+				break
+			}
+		}
+	}
+	w.write(16, ranges)
+	for i := 0; i < 16; i++ {
+		if ranges&(1<<(15-uint(i))) == 0 {
+			continue
+		}
+		var bits uint64
+		for j := 0; j < 16; j++ {
+			if inUse[i*16+j] {
+				bits |= 1 << (15 - uint(j))
+			}
+		}
+		w.write(16, bits)
+	}
+
+	// The format requires at least two tables, so the same one is written twice and only the first is ever selected.
+	const tables = 2
+	selectors := (len(symbols) + groupSize - 1) / groupSize
+	w.write(3, tables)
+	w.write(15, uint64(selectors))
+	for i := 0; i < selectors; i++ {
+		w.write(1, 0)
+	}
+	for t := 0; t < tables; t++ {
+		cur := lengths[0]
+		w.write(5, uint64(cur))
+		for _, l := range lengths {
+			for ; cur < l; cur++ {
+				w.write(2, 2)
+			}
+			for ; cur > l; cur-- {
+				w.write(2, 3)
+			}
+			w.write(1, 0)
+		}
+	}
+	for _, s := range symbols {
+		w.write(uint(lengths[s]), uint64(codes[s]))
+	}
+}
+
+type huffmanNode struct {
+	weight      int
+	symbol      int
+	left, right *huffmanNode
+}
+
+type huffmanHeap []*huffmanNode
+
+func (h huffmanHeap) Len() int            { return len(h) }
+func (h huffmanHeap) Less(i, j int) bool  { return h[i].weight < h[j].weight }
+func (h huffmanHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *huffmanHeap) Push(x interface{}) { *h = append(*h, x.(*huffmanNode)) }
+func (h *huffmanHeap) Pop() interface{} {
+	old := *h
+	n := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return n
+}
+
+//codeLengths Returns Huffman code lengths for every symbol, no longer than maxCodeLength.  Every symbol gets a code,
+// even those that never occur, as the format requires.  When the codes come out too long, the frequencies are
+// flattened and the tree is rebuilt, the same way the reference encoder does it.
+func codeLengths(freqs []int) []int {
+	weights := make([]int, len(freqs))
+	for i, f := range freqs {
+		weights[i] = f
+		if weights[i] == 0 {
+			weights[i] = 1
+		}
+	}
+	lengths := make([]int, len(freqs))
+	for {
+		h := make(huffmanHeap, len(weights))
+		for i, w := range weights {
+			h[i] = &huffmanNode{weight: w, symbol: i}
+		}
+		heap.Init(&h)
+		for h.Len() > 1 {
+			a := heap.Pop(&h).(*huffmanNode)
+			b := heap.Pop(&h).(*huffmanNode)
+			heap.Push(&h, &huffmanNode{weight: a.weight + b.weight, symbol: -1, left: a, right: b})
+		}
+		tooLong := false
+		var walk func(node *huffmanNode, depth int)
+		walk = func(node *huffmanNode, depth int) {
+			if node.left == nil {
+				lengths[node.symbol] = depth
+				if depth > maxCodeLength {
+					tooLong = true
+				}
+				return
+			}
+			walk(node.left, depth+1)
+			walk(node.right, depth+1)
+		}
+		walk(h[0], 0)
+		if !tooLong {
+			return lengths
+		}
+		for i := range weights {
+			weights[i] = 1 + weights[i]/2
+		}
+	}
+}
+
+//assignCodes Returns the canonical Huffman codes for the provided code lengths, assigned in the order bzip2 expects.
+func assignCodes(lengths []int) []uint32 {
+	codes := make([]uint32, len(lengths))
+	var code uint32
+	for l := 1; l <= maxCodeLength; l++ {
+		for i, length := range lengths {
+			if length == l {
+				codes[i] = code
+				code++
+			}
+		}
+		code <<= 1
+	}
+	return codes
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright (c) 2020 Zachariah Knight <aeros.storkpk@gmail.com>
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted, provided that the above copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ *
+ */
+
+package jag
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"io/ioutil"
+	"math/rand"
+	"testing"
+)
+
+func TestCompressRoundTrip(t *testing.T) {
+	random := make([]byte, 64*1024)
+	rand.New(rand.NewSource(1)).Read(random)
+	// Long enough to span several of the 100k blocks that 'BZh1' allows
+	text := bytes.Repeat([]byte("The quick brown fox jumps over the lazy dog. "), 6000)
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", []byte{}},
+		{"single byte", []byte{'a'}},
+		{"short runs", []byte("aaaabbbbbccccccd")},
+		{"long run", bytes.Repeat([]byte{0}, 1000)},
+		{"all byte values", func() []byte {
+			data := make([]byte, 256)
+			for i := range data {
+				data[i] = byte(i)
+			}
+			return data
+		}()},
+		{"random", random},
+		{"multiple blocks", text},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			compressed := compress(test.data)
+			if !bytes.HasPrefix(compressed, []byte("BZh1")) {
+				t.Fatalf("compressed stream starts with %q, want BZh1", compressed[:4])
+			}
+			out, err := ioutil.ReadAll(bzip2.NewReader(bytes.NewReader(compressed)))
+			if err != nil {
+				t.Fatalf("compress/bzip2 could not decompress the stream: %v", err)
+			}
+			if !bytes.Equal(out, test.data) {
+				t.Fatalf("round trip returned %d bytes that differ from the %d bytes compressed", len(out), len(test.data))
+			}
+		})
+	}
+}
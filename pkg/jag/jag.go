@@ -13,68 +13,245 @@ import (
 	"bytes"
 	"compress/bzip2"
 	"encoding/binary"
+	"errors"
 	"io"
 	"io/ioutil"
+	"os"
 	"strings"
 
 	"github.com/spkaeros/rscgo/pkg/log"
+	"github.com/spkaeros/rscgo/pkg/strutil"
 )
 
-// TODO: Parse metadata into meaningful data structures for easier reading and to provide a better API
-
 //Archive Represents a JAG archive, which is a bzip2 compressed file format to hold many files in one more easily.
+//
+// An archive is either compressed as a whole, in which case its files are stored uncompressed inside of it, or each of
+// its files is compressed on its own.  Either way, the client and Archive.Write expect the bzip2 streams without the
+// 'BZh1' header that bzip2 normally begins with.
 type Archive struct {
 	//FileCount How many files this JAG archive contains
 	FileCount int
-	//MetaData The meta data for each file.  4-byte int nameHash, 3-byte decompLen, 3-byte compLen
+	//MetaData The meta data for each file, as it was read.  4-byte int nameHash, 3-byte decompLen, 3-byte compLen
 	MetaData []byte
-	//FileData The raw, consecutive file data.
+	//FileData The raw, consecutive file data, as it was read.
 	FileData []byte
 	Files    []Entry
 }
 
+//Entry A single file held in a JAG archive.
 type Entry struct {
+	//Data The file as it is stored in the archive; compressed if CompressedLength differs from Length.
 	Data             []byte
 	CompressedLength int
 	Length           int
 	Index            int
+	//Hash The hash of the file's name; see strutil.JagHash
 	Hash             int
 }
 
-//decoder Returns an io.Reader that reads JAG archive file data and turns it into the raw, decompressed data that
-// made it. In order to get the standard library to decode this strange antiquated file format, I had to remove the JAG
-// archive header (2x3-byte ints, decompressed, then compressed length) then manually insert a BZ2 header
-// ('B','Z','h','[1-9]', the last byte is the compression level, default 1) before the compressed payload.
-func decoder(data []byte) io.Reader {
-	return bzip2.NewReader(bytes.NewReader(append([]byte{'B', 'Z', 'h', '1'}, data[6:]...)))
+//Compression Selects how Archive.Write compresses an archive.
+type Compression int
+
+const (
+	//CompressArchive compresses the entire archive as a single bzip2 stream.  Most of the client's archives use this.
+	CompressArchive Compression = iota
+	//CompressEntries compresses each file in the archive as its own bzip2 stream.
+	CompressEntries
+)
+
+//headerLength The length of the header that comes before the archive body: 3-byte decompLen, 3-byte compLen
+const headerLength = 6
+
+//maxLength The longest length that fits in the 3 bytes the format stores lengths in.
+const maxLength = 1<<24 - 1
+
+//decompress Decompresses a bzip2 stream that is missing its header, expecting length bytes out of it.
+// In order to get the standard library to decode this strange antiquated file format, I had to manually insert a BZ2
+// header ('B','Z','h','[1-9]', the last byte is the compression level, default 1) before the compressed payload.
+func decompress(data []byte, length int) ([]byte, error) {
+	buf := make([]byte, length)
+	_, err := io.ReadFull(bzip2.NewReader(io.MultiReader(bytes.NewReader([]byte{'B', 'Z', 'h', '1'}), bytes.NewReader(data))), buf)
+	if err != nil && !strings.HasSuffix(err.Error(), "continuation file") {
+		return nil, err
+	}
+	return buf, nil
+}
+
+func readLength(b []byte) int {
+	return int(b[0])<<16 | int(b[1])<<8 | int(b[2])
+}
+
+func putLength(b []byte, n int) {
+	b[0], b[1], b[2] = byte(n>>16), byte(n>>8), byte(n)
 }
 
 //New Returns a new JAG archive, with the entry count, file metadata, and file data parsed to make reading the data much simpler.
 func New(file string) *Archive {
-	fileData, err := ioutil.ReadFile(file)
+	archive, err := Open(file)
 	if err != nil {
 		log.Warn("Problem occurred attempting to read the JAG archive:", err)
 		return nil
 	}
-	buf, err := ioutil.ReadAll(decoder(fileData))
-	if err != nil && !strings.HasSuffix(err.Error(), "continuation file") {
-		log.Warn("Problem occurred attempting to decompress the JAG archive:", err)
-		return nil
+	return archive
+}
+
+//Open Reads and parses the JAG archive stored in file.
+func Open(file string) (*Archive, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+//Read Reads and parses a JAG archive from r.
+func Read(r io.Reader) (*Archive, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < headerLength {
+		return nil, errors.New("jag: archive is too short to hold a header")
+	}
+	length, compressedLength := readLength(data), readLength(data[3:])
+	if len(data)-headerLength < compressedLength {
+		return nil, errors.New("jag: archive is shorter than its header says")
+	}
+	buf := data[headerLength : headerLength+compressedLength]
+	if length != compressedLength {
+		if buf, err = decompress(buf, length); err != nil {
+			return nil, err
+		}
+	}
+	if len(buf) < 2 {
+		return nil, errors.New("jag: archive is too short to hold a file count")
+	}
+	count := int(binary.BigEndian.Uint16(buf))
+	if len(buf) < count*10+2 {
+		return nil, errors.New("jag: archive is too short to hold its file metadata")
 	}
-	count := int(binary.BigEndian.Uint16(buf[:]))
 	archive := &Archive{count, buf[2 : count*10+2], buf[count*10+2:], make([]Entry, 0, count)}
 	fileOffset := 0
 	headerOffset := 0
-	idx := 0
 	// Sectors begin at: offsetX=48, offsetY=96
 	for i := 0; i < archive.FileCount; i++ {
-		hash := int(binary.BigEndian.Uint32(archive.MetaData[headerOffset:]))
-		length := int(uint32(archive.MetaData[headerOffset+4]&0xFF)<<16 | uint32(archive.MetaData[headerOffset+5]&0xFF)<<8 | uint32(archive.MetaData[headerOffset+6]&0xFF))
-		compressedLength := int(uint32(archive.MetaData[headerOffset+7]&0xFF)<<16 | uint32(archive.MetaData[headerOffset+8]&0xFF)<<8 | uint32(archive.MetaData[headerOffset+9]&0xFF))
-		archive.Files = append(archive.Files, Entry{Hash: hash, Length: length, CompressedLength: compressedLength, Index: idx, Data: archive.FileData[fileOffset : fileOffset+compressedLength]})
-		idx++
+		// Hashes are signed, so that they match what strutil.JagHash returns for the file's name
+		hash := int(int32(binary.BigEndian.Uint32(archive.MetaData[headerOffset:])))
+		length := readLength(archive.MetaData[headerOffset+4:])
+		compressedLength := readLength(archive.MetaData[headerOffset+7:])
+		if fileOffset+compressedLength > len(archive.FileData) {
+			return nil, errors.New("jag: file data runs past the end of the archive")
+		}
+		archive.Files = append(archive.Files, Entry{Hash: hash, Length: length, CompressedLength: compressedLength, Index: i, Data: archive.FileData[fileOffset : fileOffset+compressedLength]})
 		headerOffset += 10
 		fileOffset += compressedLength
 	}
-	return archive
+	return archive, nil
+}
+
+//Decompress Returns the contents of the file, decompressing them if they are stored compressed.
+func (e *Entry) Decompress() ([]byte, error) {
+	if e.Length == e.CompressedLength {
+		return e.Data, nil
+	}
+	return decompress(e.Data, e.Length)
+}
+
+//Lookup Returns the entry for the file with the provided name, or nil if the archive does not hold it.
+func (a *Archive) Lookup(name string) *Entry {
+	return a.LookupHash(strutil.JagHash(name))
+}
+
+//LookupHash Returns the entry for the file whose name hashes to hash, or nil if the archive does not hold it.
+func (a *Archive) LookupHash(hash int) *Entry {
+	for i := range a.Files {
+		if int32(a.Files[i].Hash) == int32(hash) {
+			return &a.Files[i]
+		}
+	}
+	return nil
+}
+
+//File Returns the decompressed contents of the file with the provided name.
+func (a *Archive) File(name string) ([]byte, error) {
+	e := a.Lookup(name)
+	if e == nil {
+		return nil, errors.New("jag: archive does not hold " + name)
+	}
+	return e.Decompress()
+}
+
+//Put Stores data in the archive under the provided name, replacing any file that already has that name.
+func (a *Archive) Put(name string, data []byte) {
+	a.PutHash(strutil.JagHash(name), data)
+}
+
+//PutHash Stores data in the archive under the provided name hash, replacing any file that already has that hash.
+func (a *Archive) PutHash(hash int, data []byte) {
+	if e := a.LookupHash(hash); e != nil {
+		e.Data, e.Length, e.CompressedLength = data, len(data), len(data)
+		return
+	}
+	a.Files = append(a.Files, Entry{Hash: int(int32(hash)), Data: data, Length: len(data), CompressedLength: len(data), Index: len(a.Files)})
+	a.FileCount = len(a.Files)
+}
+
+//Remove Removes the file with the provided name from the archive.  Returns true if there was a file to remove.
+func (a *Archive) Remove(name string) bool {
+	hash := int32(strutil.JagHash(name))
+	for i := range a.Files {
+		if int32(a.Files[i].Hash) == hash {
+			a.Files = append(a.Files[:i], a.Files[i+1:]...)
+			for j := i; j < len(a.Files); j++ {
+				a.Files[j].Index = j
+			}
+			a.FileCount = len(a.Files)
+			return true
+		}
+	}
+	return false
+}
+
+//Write Encodes the archive and writes it to w, compressed the way mode describes.
+func (a *Archive) Write(w io.Writer, mode Compression) error {
+	if len(a.Files) > 0xFFFF {
+		return errors.New("jag: too many files to fit in an archive")
+	}
+	meta := make([]byte, 2+len(a.Files)*10)
+	binary.BigEndian.PutUint16(meta, uint16(len(a.Files)))
+	var files bytes.Buffer
+	for i := range a.Files {
+		data, err := a.Files[i].Decompress()
+		if err != nil {
+			return err
+		}
+		stored := data
+		if mode == CompressEntries {
+			stored = compress(data)[4:]
+		}
+		if len(data) > maxLength || len(stored) > maxLength {
+			return errors.New("jag: file is too large to fit in an archive")
+		}
+		binary.BigEndian.PutUint32(meta[2+i*10:], uint32(a.Files[i].Hash))
+		putLength(meta[2+i*10+4:], len(data))
+		putLength(meta[2+i*10+7:], len(stored))
+		files.Write(stored)
+	}
+	out := append(meta, files.Bytes()...)
+	length := len(out)
+	if mode == CompressArchive {
+		out = compress(out)[4:]
+	}
+	if length > maxLength || len(out) > maxLength {
+		return errors.New("jag: archive is too large")
+	}
+	header := make([]byte, headerLength)
+	putLength(header, length)
+	putLength(header[3:], len(out))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(out)
+	return err
 }
@@ -0,0 +1,174 @@
+/*
+ * Copyright (c) 2020 Zachariah Knight <aeros.storkpk@gmail.com>
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted, provided that the above copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ *
+ */
+
+// jagtool lists, extracts and packs JAG archives.
+//
+// Build it the same way as the server: go build -o bin/jagtool pkg/jagtool.go
+//
+// JAG archives only store a hash of each file's name.  When extracting, files are named by their hash unless a list of
+// candidate names is given with --names.  When packing, a file whose name is a plain number is stored under that number
+// as its hash, so that extracted archives can be packed again as they were.
+//
+// Examples:
+//	jagtool list data/landscape.jag
+//	jagtool extract -n sectors.txt data/landscape.jag ./landscape
+//	jagtool pack --update data/landscape.jag ./landscape/h0x50y50
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/spkaeros/rscgo/pkg/jag"
+	"github.com/spkaeros/rscgo/pkg/strutil"
+)
+
+type listCommand struct {
+	Names string `short:"n" long:"names" description:"A file listing candidate file names, one per line"`
+	Args  struct {
+		Archive string `positional-arg-name:"archive"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+type extractCommand struct {
+	Names string `short:"n" long:"names" description:"A file listing candidate file names, one per line"`
+	Args  struct {
+		Archive string `positional-arg-name:"archive"`
+		Output  string `positional-arg-name:"directory"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+type packCommand struct {
+	Entries bool `short:"e" long:"entries" description:"Compress each file on its own, instead of the archive as a whole"`
+	Update  bool `short:"u" long:"update" description:"Add the files to the existing archive, replacing any with the same name"`
+	Args    struct {
+		Archive string   `positional-arg-name:"archive"`
+		Files   []string `positional-arg-name:"files"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+//readNames Reads a list of candidate file names and maps them by their hash.
+func readNames(file string) (map[int32]string, error) {
+	names := make(map[int32]string)
+	if file == "" {
+		return names, nil
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if name := scanner.Text(); name != "" {
+			names[int32(strutil.JagHash(name))] = name
+		}
+	}
+	return names, scanner.Err()
+}
+
+func entryName(names map[int32]string, e jag.Entry) string {
+	if name, ok := names[int32(e.Hash)]; ok {
+		return name
+	}
+	return strconv.Itoa(e.Hash)
+}
+
+func (c *listCommand) Execute(args []string) error {
+	archive, err := jag.Open(c.Args.Archive)
+	if err != nil {
+		return err
+	}
+	names, err := readNames(c.Names)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d files\n", archive.FileCount)
+	for _, e := range archive.Files {
+		fmt.Printf("%-16s %10d bytes %10d stored\n", entryName(names, e), e.Length, e.CompressedLength)
+	}
+	return nil
+}
+
+func (c *extractCommand) Execute(args []string) error {
+	archive, err := jag.Open(c.Args.Archive)
+	if err != nil {
+		return err
+	}
+	names, err := readNames(c.Names)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.Args.Output, 0755); err != nil {
+		return err
+	}
+	for _, e := range archive.Files {
+		data, err := e.Decompress()
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(c.Args.Output, entryName(names, e)), data, 0644); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Extracted %d files to %s\n", len(archive.Files), c.Args.Output)
+	return nil
+}
+
+func (c *packCommand) Execute(args []string) error {
+	archive := &jag.Archive{}
+	if c.Update {
+		var err error
+		if archive, err = jag.Open(c.Args.Archive); err != nil {
+			return err
+		}
+	}
+	for _, file := range c.Args.Files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		name := filepath.Base(file)
+		if hash, err := strconv.Atoi(name); err == nil {
+			archive.PutHash(hash, data)
+		} else {
+			archive.Put(name, data)
+		}
+	}
+	mode := jag.CompressArchive
+	if c.Entries {
+		mode = jag.CompressEntries
+	}
+	out, err := os.Create(c.Args.Archive)
+	if err != nil {
+		return err
+	}
+	if err := archive.Write(out, mode); err != nil {
+		out.Close()
+		return err
+	}
+	fmt.Printf("Packed %d files into %s\n", len(archive.Files), c.Args.Archive)
+	return out.Close()
+}
+
+func main() {
+	parser := flags.NewParser(nil, flags.Default)
+	parser.AddCommand("list", "List the files in an archive", "", &listCommand{})
+	parser.AddCommand("extract", "Extract every file in an archive into a directory", "", &extractCommand{})
+	parser.AddCommand("pack", "Pack files into an archive", "", &packCommand{})
+	if _, err := parser.Parse(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,140 @@
+/*
+ * Copyright (c) 2020 Zachariah Knight <aeros.storkpk@gmail.com>
+ *
+ * Permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted, provided that the above copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ *
+ */
+
+// maprender renders areas of the game's landscape to PNG images.
+//
+// Build it the same way as the server: go build -o bin/maprender pkg/maprender.go
+//
+// Examples:
+//	maprender -o lumbridge.png --area 100,620,160,680 --scale 8 --walls --blocks --objects
+//	maprender -o plane0.png --plane 0 --npcs --items
+package main
+
+import (
+	"image"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"github.com/jessevdk/go-flags"
+
+	"github.com/spkaeros/rscgo/pkg/config"
+	"github.com/spkaeros/rscgo/pkg/db"
+	"github.com/spkaeros/rscgo/pkg/game/render"
+	"github.com/spkaeros/rscgo/pkg/game/world"
+	"github.com/spkaeros/rscgo/pkg/log"
+)
+
+var renderFlags struct {
+	Output  string `short:"o" long:"output" description:"The PNG file to write the map image to" default:"map.png"`
+	Plane   int    `short:"p" long:"plane" description:"Render this entire plane (0-3); ignored when --area is given" default:"0"`
+	Area    string `short:"a" long:"area" description:"Render only this area of world coordinates, as minX,minY,maxX,maxY (max exclusive, none negative)"`
+	Scale   int    `short:"s" long:"scale" description:"The width of each tile, in pixels" default:"4"`
+	Walls   bool   `short:"w" long:"walls" description:"Draw walls from the collision data"`
+	Blocks  bool   `short:"b" long:"blocks" description:"Mark entirely blocked tiles"`
+	Npcs    bool   `short:"n" long:"npcs" description:"Draw NPC spawns, and their wander bounds"`
+	Items   bool   `short:"i" long:"items" description:"Mark ground item spawns"`
+	Objects bool   `short:"O" long:"objects" description:"Write scenary object IDs on their tiles"`
+	Config  string `short:"c" long:"config" description:"Specify the TOML configuration file to load game settings from" default:"config.toml"`
+}
+
+//run Helper function for concurrently running a bunch of functions and waiting for them to complete
+func run(fns ...func()) {
+	w := &sync.WaitGroup{}
+	for _, fn := range fns {
+		w.Add(1)
+		go func(fn func()) {
+			defer w.Done()
+			fn()
+		}(fn)
+	}
+	w.Wait()
+}
+
+func main() {
+	if _, err := flags.Parse(&renderFlags); err != nil {
+		os.Exit(1)
+	}
+	config.TomlConfig.DataDir = "./data/"
+	config.TomlConfig.DbioDefs = config.TomlConfig.DataDir + "dbio.conf"
+	config.TomlConfig.Database.PlayerDriver = "sqlite3"
+	config.TomlConfig.Database.WorldDriver = "sqlite3"
+	config.TomlConfig.Database.PlayerDB = "file:./data/players.db"
+	config.TomlConfig.Database.WorldDB = "file:./data/world.db"
+	if _, err := toml.DecodeFile(renderFlags.Config, &config.TomlConfig); err != nil {
+		log.Warn("Error decoding server config, using defaults:", err)
+	}
+	if _, err := toml.DecodeFile(config.TomlConfig.DbioDefs, &config.TomlConfig.Database); err != nil {
+		log.Warn("Error decoding database i/o config, using defaults:", err)
+	}
+
+	if renderFlags.Plane < 0 || renderFlags.Plane > 3 {
+		log.Warn("Invalid plane:", renderFlags.Plane, "is not between 0 and 3")
+		os.Exit(1)
+	}
+	opts := render.Options{Area: render.PlaneArea(renderFlags.Plane), Scale: renderFlags.Scale}
+	if len(renderFlags.Area) > 0 {
+		var bounds [4]int
+		fields := strings.Split(renderFlags.Area, ",")
+		for i := range bounds {
+			if len(fields) != len(bounds) {
+				log.Warn("Invalid area; expected minX,minY,maxX,maxY")
+				os.Exit(1)
+			}
+			n, err := strconv.Atoi(strings.TrimSpace(fields[i]))
+			if err != nil {
+				log.Warn("Invalid area coordinate:", err)
+				os.Exit(1)
+			}
+			if n < 0 {
+				log.Warn("Invalid area coordinate:", n, "is negative")
+				os.Exit(1)
+			}
+			bounds[i] = n
+		}
+		opts.Area = image.Rect(bounds[0], bounds[1], bounds[2], bounds[3])
+	}
+	if renderFlags.Walls {
+		opts.Layers |= render.LayerWalls
+	}
+	if renderFlags.Blocks {
+		opts.Layers |= render.LayerBlocks
+	}
+	if renderFlags.Npcs {
+		opts.Layers |= render.LayerNpcs
+	}
+	if renderFlags.Items {
+		opts.Layers |= render.LayerItems
+	}
+	if renderFlags.Objects {
+		opts.Layers |= render.LayerObjectIDs
+	}
+
+	// Same load phases as the game server; collision data depends on the definitions, and spawns on the collision data
+	db.ConnectEntityService()
+	run(db.LoadTileDefinitions, db.LoadObjectDefinitions, db.LoadBoundaryDefinitions, db.LoadItemDefinitions, db.LoadNpcDefinitions)
+	world.LoadCollisionData()
+	if opts.Layers&(render.LayerBlocks|render.LayerNpcs|render.LayerItems|render.LayerObjectIDs) != 0 {
+		run(db.LoadObjectLocations, db.LoadNpcLocations, db.LoadItemLocations)
+	}
+
+	file, err := os.Create(renderFlags.Output)
+	if err != nil {
+		log.Warn("Could not open output file:", err)
+		os.Exit(1)
+	}
+	defer file.Close()
+	if err := render.WritePNG(file, opts); err != nil {
+		log.Warn("Could not write map image:", err)
+		os.Exit(1)
+	}
+	log.Debug("Rendered", opts.Area, "to", renderFlags.Output)
+}
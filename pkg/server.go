@@ -136,6 +136,9 @@ func main() {
 	config.TomlConfig.DataDir = "./data/"
 	config.TomlConfig.DbioDefs = config.TomlConfig.DataDir + "dbio.conf"
 	config.TomlConfig.PacketHandlerFile = config.TomlConfig.DataDir + "packets.toml"
+	config.TomlConfig.GroundItems.PrivateTicks = 100
+	config.TomlConfig.GroundItems.PublicTicks = 200
+	config.TomlConfig.Snapshot.File = "./data/world.snapshot"
 	config.TomlConfig.Crypto.HashComplexity = 15
 	config.TomlConfig.Crypto.HashLength = 32
 	config.TomlConfig.Crypto.HashMemory = 8
@@ -158,6 +161,8 @@ func main() {
 		return
 	}
 	run(db.ConnectEntityService, openUserDatabase)
+	world.DefaultSpawnService = db.DefaultEntityService
+	world.DefaultDefinitionService = db.DefaultEntityService
 	if cliFlags.Port > 0 {
 		config.TomlConfig.Port = cliFlags.Port
 	}
@@ -170,17 +175,33 @@ func main() {
 	// Three init phases after data backend is connected--Entity definitions, then tile collision bitmask loading, followed by entity spawn locations
 	// So, the order here of these three phases is important.  If you attempt to load object spawn locations during the same phase as the collision
 	// data, it will result in a world filled with objects that are not solid.  Many similar bugs possible.  Best just to leave this be.
-	run(db.LoadTileDefinitions, db.LoadObjectDefinitions, db.LoadBoundaryDefinitions, db.LoadItemDefinitions, db.LoadNpcDefinitions)
+	run(db.LoadTileDefinitions, db.LoadObjectDefinitions, db.LoadBoundaryDefinitions, db.LoadItemDefinitions, db.LoadNpcDefinitions, db.LoadSpellDefinitions, db.LoadPrayerDefinitions, db.LoadDropDefinitions)
 	run(world.LoadCollisionData, world.UnmarshalPackets, world.RunScripts)
 		// world.LoadCollisionData, world.UnmarshalPackets, world.RunScripts)
-	run(db.LoadObjectLocations, db.LoadNpcLocations, db.LoadItemLocations)
+	run(db.LoadObjectLocations, db.LoadNpcLocations, db.LoadItemLocations, db.LoadZones)
+	world.RecordObjectSpawns()
+	if config.RestoreSnapshot() {
+		if err := world.RestoreSnapshot(""); err != nil {
+			log.Warn("Could not restore world snapshot:", err)
+		}
+	}
 
 	if config.Verbose() {
 		log.Debug("Loaded collision data from", len(world.Sectors), "map sectors")
-		log.Debug("Loaded", len(definitions.TileOverlays), "tile types")
+		log.Debug("Loaded", len(definitions.Current().Tiles), "tile types")
 		log.Debug("Loaded", world.PacketCount(), "packet types, with handlers for", world.HandlerCount(), "of them")
-		log.Debug("Loaded", world.ItemIndexer.Load(), "items and", len(definitions.Items), "item types")
-		log.Debug("Loaded", world.Npcs.Size(), "NPCs and", len(definitions.Npcs), "NPC types")
+		log.Debug("Loaded", world.ItemIndexer.Size(), "items and", len(definitions.Current().Items), "item types")
+		log.Debug("Loaded", world.Npcs.Size(), "NPCs and", len(definitions.Current().Npcs), "NPC types")
+		log.Debug("Loaded", len(definitions.Current().Spells), "spell types")
+		log.Debug("Loaded", len(definitions.Current().Prayers), "prayer types")
+		drops := 0
+		for _, table := range definitions.Current().Drops {
+			if table.Defined() {
+				drops++
+			}
+		}
+		log.Debug("Loaded", drops, "NPC drop tables")
+		log.Debug("Loaded", len(world.Zones()), "zones")
 		scenary, boundary := 0, 0
 		for _, v := range world.GetAllObjects() {
 			if v.(*world.Object).Boundary {
@@ -189,8 +210,8 @@ func main() {
 				scenary++
 			}
 		}
-		log.Debug("Loaded", scenary, "scenary objects, and", len(definitions.ScenaryObjects), "scenary types.")
-		log.Debug("Loaded", boundary, "boundary objects, and", len(definitions.BoundaryObjects), "boundary types")
+		log.Debug("Loaded", scenary, "scenary objects, and", len(definitions.Current().Scenary), "scenary types.")
+		log.Debug("Loaded", boundary, "boundary objects, and", len(definitions.Current().Boundaries), "boundary types")
 		log.Debug("Loading all game entitys took:", time.Since(start).Seconds(), "seconds")
 		if config.Verbosity >= 2 {
 			log.Debugf("Triggers[\n\t%d item actions,\n\t%d scenary actions,\n\t%d boundary actions,\n\t%d npc actions,\n\t%d item->boundary actions,\n\t%d item->scenary actions,\n\t%d attacking NPC actions,\n\t%d killing NPC actions\n];\n", len(world.ItemTriggers), len(world.ObjectTriggers), len(world.BoundaryTriggers), len(world.NpcTalkList), len(world.InvOnBoundaryTriggers), len(world.InvOnObjectTriggers), len(world.NpcAtkTriggers), len(world.NpcDeathTriggers))
@@ -288,6 +309,7 @@ func (s *Server) Start() {
 					if fn := p.TickAction(); fn != nil && !fn() {
 						p.ResetTickAction()
 					}
+					p.DrainPrayer()
 					p.TraversePath()
 				})
 				world.Npcs.RangeNpcs(func(n *world.NPC) bool {
@@ -295,6 +317,10 @@ func (s *Server) Start() {
 						return false
 					}
 
+					if n.Provoked() {
+						n.TraversePath()
+						return false
+					}
 					if n.Aggressive() {
 						if _, ok := n.Var("targetPlayer"); ok {
 							return false
@@ -384,6 +410,9 @@ func (s *Server) Start() {
 //Stop This will stop the game instance, if it is running.
 func (s *Server) Stop() {
 	log.Debug("Stopping...")
+	if err := world.SaveSnapshot(""); err != nil {
+		log.Warn("Could not save world snapshot:", err)
+	}
 	os.Exit(0)
 }
 
@@ -399,6 +428,9 @@ func closestPlayer(n *world.NPC) *world.Player {
 	var closest *world.Player
 	distance := math.Pow(8.0,2)
 	world.Region(n.X(), n.Y()).Players.RangePlayers(func(p1 *world.Player) bool {
+		if p1.PrayerActivated(world.PrayerParalyzeMonster) {
+			return false
+		}
 		if p1.EuclideanDistance(n) < distance {
 			closest = p1
 			distance = p1.EuclideanDistance(n)